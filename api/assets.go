@@ -0,0 +1,53 @@
+package api
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// staticMaxAge is how long browsers may cache the static assets. The vendored
+// libraries sit in versioned directories, so a new version gets a new URL.
+const staticMaxAge = 365 * 24 * time.Hour
+
+// parseTemplates parses the templates matching patterns in fsys.
+func parseTemplates(fsys fs.FS, funcs template.FuncMap, patterns ...string) (*template.Template, error) {
+	return template.New("").Funcs(funcs).ParseFS(fsys, patterns...)
+}
+
+// reloadingRender parses the templates again on every render, so that edits
+// to the files on disk show up without restarting the server.
+type reloadingRender struct {
+	fsys     fs.FS
+	patterns []string
+	funcs    template.FuncMap
+}
+
+func (r reloadingRender) Instance(name string, data any) render.Render {
+	templates, err := parseTemplates(r.fsys, r.funcs, r.patterns...)
+	if err != nil {
+		panic(fmt.Errorf("cannot reload templates: %w", err))
+	}
+	return render.HTML{Template: templates, Name: name, Data: data}
+}
+
+// staticCacheControl lets browsers keep the static assets without asking
+// again until they expire.
+func staticCacheControl(maxAge time.Duration) gin.HandlerFunc {
+	value := fmt.Sprintf("public, max-age=%d, immutable", int(maxAge.Seconds()))
+	return func(ctx *gin.Context) {
+		ctx.Header("Cache-Control", value)
+		ctx.Next()
+	}
+}
+
+// serveStatic serves the files of fsys under /static.
+func serveStatic(router *gin.Engine, fsys fs.FS) {
+	assets := router.Group("/static", staticCacheControl(staticMaxAge))
+	assets.StaticFS("/", http.FS(fsys))
+}
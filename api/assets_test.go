@@ -0,0 +1,152 @@
+package api
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	mockdb "first-app/todo_go/db/mock"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeStatic(t *testing.T) {
+	testCases := []struct {
+		name          string
+		targetUrl     string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			targetUrl: "/static/lib/font-awesome-4.7.0/css/font-awesome.min.css",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Type"), "text/css")
+				require.Equal(t, "public, max-age=31536000, immutable", recorder.Header().Get("Cache-Control"))
+				require.Contains(t, recorder.Body.String(), "Font Awesome 4.7.0")
+			},
+		},
+		{
+			name:      "Font",
+			targetUrl: "/static/lib/font-awesome-4.7.0/fonts/fontawesome-webfont.woff2",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotZero(t, recorder.Body.Len())
+			},
+		},
+		{
+			name:      "Bootstrap CSS",
+			targetUrl: "/static/lib/bootstrap-4.6.1/css/bootstrap.min.css",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Type"), "text/css")
+				requireIntegrity(t, recorder.Body.Bytes(), "sha384-zCbKRCUGaJDkqS1kPbPd7TveP5iyJE0EjAuZQTgFLD2ylzuqKfdKlfG/eSrtxUkn")
+			},
+		},
+		{
+			name:      "Bootstrap JS",
+			targetUrl: "/static/lib/bootstrap-4.6.1/js/bootstrap.bundle.min.js",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Type"), "javascript")
+				requireIntegrity(t, recorder.Body.Bytes(), "sha384-fQybjgWLrvvRgtW6bFlB7jaZrFsaBXjsOMm/tB9LTS58ONXgqbR9W8oWht/amnpF")
+			},
+		},
+		{
+			name:      "jQuery",
+			targetUrl: "/static/lib/jquery-3.5.1/jquery.slim.min.js",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Type"), "javascript")
+				requireIntegrity(t, recorder.Body.Bytes(), "sha384-DfXdz2htPH0lsSSs5nCTpuj/zy4C+OGpamoFVy38MVBnE+IbbVYUew+OrCXaRkfj")
+			},
+		},
+		{
+			name:      "Not Found",
+			targetUrl: "/static/lib/missing.js",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := newTestServer(mockdb.NewMockRepo(ctrl))
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, tc.targetUrl, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// requireIntegrity checks body against the subresource integrity hash the
+// templates load it with.
+func requireIntegrity(t *testing.T, body []byte, integrity string) {
+	t.Helper()
+
+	sum := sha512.Sum384(body)
+	require.Equal(t, integrity, "sha384-"+base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+func TestEmbeddedTemplates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The embedded templates do not depend on the working directory.
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() {
+		require.NoError(t, os.Chdir(wd))
+	})
+
+	server := newTestServer(mockdb.NewMockRepo(ctrl))
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/login", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), "/static/lib/bootstrap-4.6.1/css/bootstrap.min.css")
+}
+
+func TestWithTemplateDir(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir := t.TempDir()
+	loginPath := filepath.Join(dir, "login.html")
+	require.NoError(t, os.WriteFile(loginPath, []byte("first version"), 0o600))
+
+	server := NewServer(mockdb.NewMockRepo(ctrl), WithSecrets(testSecrets), WithTemplateDir(dir))
+
+	render := func() string {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/login", nil)
+		require.NoError(t, err)
+
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		return recorder.Body.String()
+	}
+
+	require.Equal(t, "first version", render())
+
+	require.NoError(t, os.WriteFile(loginPath, []byte("second version"), 0o600))
+	require.Equal(t, "second version", render())
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"first-app/todo_go/util"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// addFlash stores a message on the session to be shown on the next page.
+func addFlash(ctx *gin.Context, message string) {
+	session := sessions.Default(ctx)
+	session.AddFlash(message)
+	if err := session.Save(); err != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when saving the session",
+			Effect:   "User does not see the result message of the last action",
+			Solution: "This might be the session cookie issue, please check the session store settings",
+			Position: "flash.go file, addFlash method",
+		}, err)
+	}
+}
+
+// popFlashes returns the messages stored by addFlash and removes them from the
+// session. It has to run before the response body is written.
+func popFlashes(ctx *gin.Context) []string {
+	session := sessions.Default(ctx)
+	flashes := session.Flashes()
+	if len(flashes) == 0 {
+		return nil
+	}
+	if err := session.Save(); err != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when saving the session",
+			Effect:   "User may see the same message again",
+			Solution: "This might be the session cookie issue, please check the session store settings",
+			Position: "flash.go file, popFlashes method",
+		}, err)
+	}
+
+	messages := make([]string, 0, len(flashes))
+	for _, flash := range flashes {
+		if message, ok := flash.(string); ok {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
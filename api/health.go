@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"errors"
+	db "first-app/todo_go/db/sqlc"
+	"first-app/todo_go/util"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// readyTimeout bounds the time the readiness checks may take together, so a
+	// hanging database fails the check instead of the orchestrator's probe.
+	readyTimeout = 2 * time.Second
+
+	checkUp   = "up"
+	checkDown = "down"
+)
+
+// dependencyCheck is the result of checking one dependency for /readyz.
+type dependencyCheck struct {
+	Status          string `json:"status"`
+	Error           string `json:"error,omitempty"`
+	Version         int64  `json:"version,omitempty"`
+	ExpectedVersion int64  `json:"expected_version,omitempty"`
+	Dirty           bool   `json:"dirty,omitempty"`
+}
+
+type readyResponse struct {
+	Status string                     `json:"status"`
+	Checks map[string]dependencyCheck `json:"checks"`
+}
+
+// livez tells that the process is running and serving requests. It does not
+// look at the dependencies, so a database outage does not get it restarted.
+func (server *Server) livez(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": "alive"})
+}
+
+// readyz tells if the instance can serve traffic: the database answers and
+// it is migrated to the version this build expects.
+func (server *Server) readyz(ctx *gin.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, readyTimeout)
+	defer cancel()
+
+	res := readyResponse{
+		Status: "ready",
+		Checks: map[string]dependencyCheck{
+			"database":   server.checkDatabase(checkCtx),
+			"migrations": server.checkMigrations(checkCtx),
+		},
+	}
+
+	status := http.StatusOK
+	for _, check := range res.Checks {
+		if check.Status != checkUp {
+			res.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+	}
+	ctx.JSON(status, res)
+}
+
+func (server *Server) checkDatabase(ctx context.Context) dependencyCheck {
+	if err := server.repo.Ping(ctx); err != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when pinging the DB",
+			Effect:   "The instance is reported as not ready and gets no traffic",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "health.go file, checkDatabase method on Ping",
+		}, err)
+		return dependencyCheck{Status: checkDown, Error: err.Error()}
+	}
+	return dependencyCheck{Status: checkUp}
+}
+
+func (server *Server) checkMigrations(ctx context.Context) dependencyCheck {
+	expected, err := db.LatestMigrationVersion(server.migrations)
+	if err != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when reading the migration files",
+			Effect:   "The instance is reported as not ready and gets no traffic",
+			Solution: "The embedded migration files are broken, please check db/migration for the DB_DRIVER and rebuild",
+			Position: "health.go file, checkMigrations method on LatestMigrationVersion",
+		}, err)
+		return dependencyCheck{Status: checkDown, Error: err.Error()}
+	}
+
+	version, err := server.repo.GetSchemaVersion(ctx)
+	if err != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the schema version from DB",
+			Effect:   "The instance is reported as not ready and gets no traffic",
+			Solution: "This might be the database connection issue, or the migrations were never run",
+			Position: "health.go file, checkMigrations method on GetSchemaVersion query",
+		}, err)
+		return dependencyCheck{Status: checkDown, Error: err.Error(), ExpectedVersion: expected}
+	}
+
+	check := dependencyCheck{
+		Status:          checkUp,
+		Version:         version.Version,
+		ExpectedVersion: expected,
+		Dirty:           version.Dirty,
+	}
+	switch {
+	case version.Dirty:
+		check.Status = checkDown
+		check.Error = fmt.Sprintf("migration %d is dirty", version.Version)
+	case version.Version != expected:
+		check.Status = checkDown
+		check.Error = fmt.Sprintf("schema is at version %d, expected %d", version.Version, expected)
+	}
+	if check.Status != checkUp {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "The DB schema is not at the expected migration version",
+			Effect:   "The instance is reported as not ready and gets no traffic",
+			Solution: "Please run the pending migrations, or fix the dirty one and force its version",
+			Position: "health.go file, checkMigrations method",
+		}, errors.New(check.Error))
+	}
+	return check
+}
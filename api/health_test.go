@@ -0,0 +1,155 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"first-app/todo_go/db/migration"
+	mockdb "first-app/todo_go/db/mock"
+	db "first-app/todo_go/db/sqlc"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLivezAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockdb.NewMockRepo(ctrl)
+	repo.EXPECT().Ping(gomock.Any()).Times(0)
+
+	server := newTestServer(repo)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/livez", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"status":"alive"}`, recorder.Body.String())
+}
+
+func TestReadyzAPI(t *testing.T) {
+	migrations, err := migration.ForDriver("mysql")
+	require.NoError(t, err)
+	expected, err := db.LatestMigrationVersion(migrations)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					Ping(gomock.Any()).
+					Times(1).
+					Return(nil)
+				repo.EXPECT().
+					GetSchemaVersion(gomock.Any()).
+					Times(1).
+					Return(db.SchemaVersion{Version: expected}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				res := requireReadyResponse(t, recorder)
+				require.Equal(t, "ready", res.Status)
+				require.Equal(t, dependencyCheck{Status: checkUp}, res.Checks["database"])
+				require.Equal(t, dependencyCheck{Status: checkUp, Version: expected, ExpectedVersion: expected}, res.Checks["migrations"])
+			},
+		},
+		{
+			name: "Database Down",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					Ping(gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+				repo.EXPECT().
+					GetSchemaVersion(gomock.Any()).
+					Times(1).
+					Return(db.SchemaVersion{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				res := requireReadyResponse(t, recorder)
+				require.Equal(t, "unavailable", res.Status)
+				require.Equal(t, checkDown, res.Checks["database"].Status)
+				require.Equal(t, sql.ErrConnDone.Error(), res.Checks["database"].Error)
+				require.Equal(t, checkDown, res.Checks["migrations"].Status)
+			},
+		},
+		{
+			name: "Pending Migrations",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					Ping(gomock.Any()).
+					Times(1).
+					Return(nil)
+				repo.EXPECT().
+					GetSchemaVersion(gomock.Any()).
+					Times(1).
+					Return(db.SchemaVersion{Version: expected - 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				res := requireReadyResponse(t, recorder)
+				require.Equal(t, checkUp, res.Checks["database"].Status)
+				require.Equal(t, checkDown, res.Checks["migrations"].Status)
+				require.Equal(t, expected-1, res.Checks["migrations"].Version)
+				require.Equal(t, expected, res.Checks["migrations"].ExpectedVersion)
+			},
+		},
+		{
+			name: "Dirty Migration",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					Ping(gomock.Any()).
+					Times(1).
+					Return(nil)
+				repo.EXPECT().
+					GetSchemaVersion(gomock.Any()).
+					Times(1).
+					Return(db.SchemaVersion{Version: expected, Dirty: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				res := requireReadyResponse(t, recorder)
+				require.Equal(t, checkDown, res.Checks["migrations"].Status)
+				require.True(t, res.Checks["migrations"].Dirty)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func requireReadyResponse(t *testing.T, recorder *httptest.ResponseRecorder) readyResponse {
+	var res readyResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	return res
+}
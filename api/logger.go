@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"first-app/todo_go/util"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	loggerKey       = "logger"
+	requestIDKey    = "requestID"
+	requestIDHeader = "X-Request-ID"
+	// maxRequestIDLen keeps a client from filling the logs through the header.
+	maxRequestIDLen = 64
+)
+
+// requestIDContextKey stores the request ID on the context.Context that the
+// handlers pass down to db.Repo.
+type requestIDContextKey struct{}
+
+// newRequestID returns a random ID to tell the log lines of one request apart.
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// validRequestID reports if an ID sent by the client is safe to log and echo:
+// letters, digits, dashes, underscores and dots only.
+func validRequestID(id string) bool {
+	if len(id) == 0 || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requestID takes the X-Request-ID sent by the client, or generates one, and
+// makes it available to the handlers, the repo calls and the response.
+func requestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		ctx.Set(requestIDKey, id)
+		ctx.Request = ctx.Request.WithContext(
+			context.WithValue(ctx.Request.Context(), requestIDContextKey{}, id),
+		)
+		ctx.Header(requestIDHeader, id)
+		ctx.Next()
+	}
+}
+
+// requestIDFrom returns the request ID carried by ctx, or an empty string
+// outside of a request.
+func requestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// requestLogger stores a logger on the context that carries the request ID,
+// method and route on every line the handlers log. A nil base logs through
+// slog.Default.
+func requestLogger(base *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := base
+		if logger == nil {
+			logger = slog.Default()
+		}
+		logger = logger.With(
+			slog.String("request_id", ctx.GetString(requestIDKey)),
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", ctx.FullPath()),
+		)
+		ctx.Set(loggerKey, logger)
+		ctx.Next()
+	}
+}
+
+// accessLog logs one line per request once it is served. Server errors are
+// logged at error level and client errors at warn level.
+func accessLog() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		path := ctx.Request.URL.Path
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		loggerFrom(ctx).LogAttrs(ctx, level, "Request served",
+			slog.String("path", path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int("bytes", max(ctx.Writer.Size(), 0)),
+			slog.String("client_ip", ctx.ClientIP()),
+		)
+	}
+}
+
+// loggerFrom returns the request logger, or the default logger outside of a
+// request, like in the background jobs.
+func loggerFrom(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// addLogAttrs adds fields to every line logged for the rest of the request,
+// like the user once they are authenticated or the todo being worked on.
+func addLogAttrs(ctx *gin.Context, args ...any) {
+	ctx.Set(loggerKey, loggerFrom(ctx).With(args...))
+}
+
+// logWarn logs a failure caused by the client, like an invalid param.
+func logWarn(ctx context.Context, details util.ErrorDetails, err error) {
+	loggedErrorsTotal.WithLabelValues("warn", details.Cause).Inc()
+	args := append(details.Attrs(), slog.Any("error", err))
+	loggerFrom(ctx).WarnContext(ctx, details.Cause, args...)
+}
+
+// logError logs a failure on the server side, like a database error.
+func logError(ctx context.Context, details util.ErrorDetails, err error) {
+	loggedErrorsTotal.WithLabelValues("error", details.Cause).Inc()
+	args := append(details.Attrs(), slog.Any("error", err))
+	loggerFrom(ctx).ErrorContext(ctx, details.Cause, args...)
+}
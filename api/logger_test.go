@@ -0,0 +1,211 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	mockdb "first-app/todo_go/db/mock"
+	db "first-app/todo_go/db/sqlc"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeLogs returns the JSON log lines written to buf.
+func decodeLogs(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var entries []map[string]any
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var entry map[string]any
+		require.NoError(t, decoder.Decode(&entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// captureLogs sends the default logger to a buffer for the rest of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() {
+		slog.SetDefault(defaultLogger)
+	})
+	return &buf
+}
+
+func TestRequestLogFields(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+
+	testCases := []struct {
+		name       string
+		todoID     int64
+		status     int
+		buildStubs func(repo *mockdb.MockRepo)
+		checkLog   func(t *testing.T, entry map[string]any)
+	}{
+		{
+			name:   "Internal Error",
+			todoID: todo.ID,
+			status: http.StatusInternalServerError,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(db.Todo{}, sql.ErrConnDone)
+			},
+			checkLog: func(t *testing.T, entry map[string]any) {
+				require.Equal(t, "ERROR", entry["level"])
+				require.Equal(t, "Error occurred when fetching the data from DB", entry["msg"])
+				require.Equal(t, float64(todo.ID), entry["todo_id"])
+				require.Equal(t, "todo_api.go file, getTodoJSON method on GetTodo query", entry["position"])
+				require.Equal(t, sql.ErrConnDone.Error(), entry["error"])
+			},
+		},
+		{
+			name:   "Invalid ID",
+			todoID: 0,
+			status: http.StatusBadRequest,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkLog: func(t *testing.T, entry map[string]any) {
+				require.Equal(t, "WARN", entry["level"])
+				require.Equal(t, "User manipulated invalid ID param", entry["msg"])
+				require.NotContains(t, entry, "todo_id")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			logs := captureLogs(t)
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			targetUrl := fmt.Sprintf("/api/v1/todos/%d", tc.todoID)
+			request, err := http.NewRequest(http.MethodGet, targetUrl, nil)
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+
+			entries := decodeLogs(t, logs)
+			require.Len(t, entries, 2)
+			for _, entry := range entries {
+				require.Equal(t, recorder.Header().Get(requestIDHeader), entry["request_id"])
+				require.Equal(t, http.MethodGet, entry["method"])
+				require.Equal(t, "/api/v1/todos/:id", entry["route"])
+				require.Equal(t, float64(user.ID), entry["user_id"])
+			}
+
+			entry := entries[0]
+			for _, key := range []string{"cause", "effect", "solution", "position"} {
+				require.NotEmpty(t, entry[key], key)
+			}
+			tc.checkLog(t, entry)
+
+			access := entries[1]
+			require.Equal(t, "Request served", access["msg"])
+			require.Equal(t, targetUrl, access["path"])
+			require.Equal(t, float64(tc.status), access["status"])
+			require.Equal(t, float64(recorder.Body.Len()), access["bytes"])
+			require.Contains(t, access, "latency")
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+
+	testCases := []struct {
+		name          string
+		requestID     string
+		checkResponse func(t *testing.T, requestID string, repoRequestID string)
+	}{
+		{
+			name:      "From Client",
+			requestID: "client-id_1.2",
+			checkResponse: func(t *testing.T, requestID string, repoRequestID string) {
+				require.Equal(t, "client-id_1.2", requestID)
+				require.Equal(t, requestID, repoRequestID)
+			},
+		},
+		{
+			name: "Generated",
+			checkResponse: func(t *testing.T, requestID string, repoRequestID string) {
+				require.Len(t, requestID, 16)
+				require.Equal(t, requestID, repoRequestID)
+			},
+		},
+		{
+			name:      "Invalid Replaced",
+			requestID: "bad id\nfake=log",
+			checkResponse: func(t *testing.T, requestID string, repoRequestID string) {
+				require.Len(t, requestID, 16)
+				require.Equal(t, requestID, repoRequestID)
+			},
+		},
+		{
+			name:      "Too Long Replaced",
+			requestID: strings.Repeat("a", maxRequestIDLen+1),
+			checkResponse: func(t *testing.T, requestID string, repoRequestID string) {
+				require.Len(t, requestID, 16)
+				require.Equal(t, requestID, repoRequestID)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var repoRequestID string
+			repo := mockdb.NewMockRepo(ctrl)
+			repo.EXPECT().
+				GetTodo(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(ctx context.Context, arg db.GetTodoParams) (db.Todo, error) {
+					repoRequestID = requestIDFrom(ctx)
+					return todo, nil
+				})
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			targetUrl := fmt.Sprintf("/api/v1/todos/%d", todo.ID)
+			request, err := http.NewRequest(http.MethodGet, targetUrl, nil)
+			require.NoError(t, err)
+			if len(tc.requestID) > 0 {
+				request.Header.Set(requestIDHeader, tc.requestID)
+			}
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+			tc.checkResponse(t, recorder.Header().Get(requestIDHeader), repoRequestID)
+		})
+	}
+}
@@ -2,16 +2,23 @@ package api
 
 import (
 	db "first-app/todo_go/db/sqlc"
+	"first-app/todo_go/util"
 	"os"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 )
 
-func newTestServer(repo db.Repo) *Server {
-	server := NewServer(repo, true)
+var testSecrets = Secrets{
+	SessionKeys: []string{util.DefaultSessionKey},
+	CSRFSecret:  util.DefaultCSRFSecret,
+}
 
-	return server
+// newTestServer builds the server with the production CSRF check, so
+// requests that change data need the token addAuthSession or addCSRFToken
+// sends along.
+func newTestServer(repo db.Repo) *Server {
+	return NewServer(repo, WithSecrets(testSecrets))
 }
 
 func TestMain(m *testing.M) {
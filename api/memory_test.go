@@ -0,0 +1,115 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	memdb "first-app/todo_go/db/memory"
+	db "first-app/todo_go/db/sqlc"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestTodoJSONWithMemoryRepo runs a user's todos through the JSON API end to
+// end, with the in-memory repo in place of the database.
+func TestTodoJSONWithMemoryRepo(t *testing.T) {
+	repo := memdb.New()
+	server := newTestServer(repo)
+
+	result, err := repo.CreateUser(context.Background(), db.CreateUserParams{
+		Username:       "memory",
+		HashedPassword: "secret",
+	})
+	require.NoError(t, err)
+	userID, err := result.LastInsertId()
+	require.NoError(t, err)
+	user, err := repo.GetUser(context.Background(), userID)
+	require.NoError(t, err)
+
+	serve := func(method, target string, body interface{}) *httptest.ResponseRecorder {
+		var reader io.Reader
+		if body != nil {
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(data)
+		}
+		request, err := http.NewRequest(method, target, reader)
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		addAuthSession(t, server, request, user)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	var milk, bread createTodoJSONResponse
+	recorder := serve(http.MethodPost, "/api/v1/todos", map[string]interface{}{
+		"title":       "Buy milk",
+		"description": "two bottles",
+		"tags":        []string{"shopping"},
+	})
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	requireBodyDecodes(t, recorder.Body, &milk)
+	require.Equal(t, db.TodoStatusOpen, milk.Status)
+	require.Len(t, milk.Tags, 1)
+
+	recorder = serve(http.MethodPost, "/api/v1/todos", map[string]interface{}{
+		"title":       "Bake bread",
+		"description": "rye",
+	})
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	requireBodyDecodes(t, recorder.Body, &bread)
+
+	var list listTodoJSONResponse
+	recorder = serve(http.MethodGet, "/api/v1/todos?tag=shopping", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	requireBodyDecodes(t, recorder.Body, &list)
+	require.Equal(t, int64(1), list.Total)
+	require.Equal(t, milk.ID, list.Todos[0].ID)
+
+	var first, second listTodoCursorJSONResponse
+	recorder = serve(http.MethodGet, "/api/v1/todos?cursor=&limit=1", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	requireBodyDecodes(t, recorder.Body, &first)
+	require.Equal(t, milk.ID, first.Todos[0].ID)
+	require.Empty(t, first.PrevCursor)
+
+	recorder = serve(http.MethodGet, "/api/v1/todos?limit=1&cursor="+first.NextCursor, nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	requireBodyDecodes(t, recorder.Body, &second)
+	require.Equal(t, bread.ID, second.Todos[0].ID)
+	require.Empty(t, second.NextCursor)
+	require.NotEmpty(t, second.PrevCursor)
+
+	var toggled db.Todo
+	recorder = serve(http.MethodPost, fmt.Sprintf("/api/v1/todos/%d/toggle", bread.ID), nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	requireBodyDecodes(t, recorder.Body, &toggled)
+	require.Equal(t, db.TodoStatusDone, toggled.Status)
+
+	var deleted db.DeleteTodoListTxResult
+	recorder = serve(http.MethodPost, "/api/v1/todos/bulk-delete", map[string]interface{}{
+		"ids": []int64{milk.ID, 999},
+	})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	requireBodyDecodes(t, recorder.Body, &deleted)
+	require.Equal(t, int64(1), deleted.Deleted)
+	require.Equal(t, []int64{999}, deleted.NotFound)
+
+	recorder = serve(http.MethodGet, fmt.Sprintf("/api/v1/todos/%d", milk.ID), nil)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	requireErrorCode(t, recorder.Body, "not_found")
+
+	list = listTodoJSONResponse{}
+	recorder = serve(http.MethodGet, "/api/v1/todos", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	requireBodyDecodes(t, recorder.Body, &list)
+	require.Equal(t, int64(1), list.Total)
+	require.Equal(t, bread.ID, list.Todos[0].ID)
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "todo"
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests served, by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to serve HTTP requests, by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	loggedErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "errors_total",
+		Help:      "Number of failures logged, by level and cause.",
+	}, []string{"level", "cause"})
+
+	dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Time taken by database queries, by query name and result.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"query", "result"})
+)
+
+// metrics counts every request and how long it took by its route, so that
+// the IDs in the paths do not end up in the labels.
+func metrics() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if len(route) == 0 {
+			route = "unmatched"
+		}
+		method := ctx.Request.Method
+		status := strconv.Itoa(ctx.Writer.Status())
+		httpRequestsTotal.WithLabelValues(method, route, status).Inc()
+		httpRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// metricsHandler serves the metrics in the Prometheus text format.
+func metricsHandler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// ObserveQuery records the duration of a database query. It is meant to be
+// passed to db.NewDriverRepo.
+func ObserveQuery(name string, duration time.Duration, err error) {
+	result := "ok"
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		result = "error"
+	}
+	dbQueryDuration.WithLabelValues(name, result).Observe(duration.Seconds())
+}
+
+// RegisterDBStats exposes the connection pool stats of conn, like the open,
+// in use and idle connections and the time spent waiting for one.
+func RegisterDBStats(conn *sql.DB) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(conn, metricsNamespace))
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"database/sql"
+	mockdb "first-app/todo_go/db/mock"
+	db "first-app/todo_go/db/sqlc"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockdb.NewMockRepo(ctrl)
+	repo.EXPECT().
+		GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+		Times(1).
+		Return(db.Todo{}, sql.ErrConnDone)
+
+	route := "/api/v1/todos/:id"
+	cause := "Error occurred when fetching the data from DB"
+	requests := httpRequestsTotal.WithLabelValues(http.MethodGet, route, "500")
+	loggedErrors := loggedErrorsTotal.WithLabelValues("error", cause)
+	requestsBefore := testutil.ToFloat64(requests)
+	errorsBefore := testutil.ToFloat64(loggedErrors)
+
+	server := newTestServer(repo)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/todos/%d", todo.ID), nil)
+	require.NoError(t, err)
+	addAuthSession(t, server, request, user)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	require.Equal(t, requestsBefore+1, testutil.ToFloat64(requests))
+	require.Equal(t, errorsBefore+1, testutil.ToFloat64(loggedErrors))
+
+	ObserveQuery("GetTodo", 5*time.Millisecond, sql.ErrNoRows)
+	ObserveQuery("GetTodo", 5*time.Millisecond, sql.ErrConnDone)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/metrics", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body := recorder.Body.String()
+	require.Contains(t, body, `todo_http_requests_total{method="GET",route="/api/v1/todos/:id",status="500"}`)
+	require.Contains(t, body, `todo_http_request_duration_seconds_bucket{method="GET",route="/api/v1/todos/:id"`)
+	require.Contains(t, body, `todo_errors_total{cause="Error occurred when fetching the data from DB",level="error"}`)
+	require.Contains(t, body, `todo_db_query_duration_seconds_count{query="GetTodo",result="ok"}`)
+	require.Contains(t, body, `todo_db_query_duration_seconds_count{query="GetTodo",result="error"}`)
+}
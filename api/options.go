@@ -0,0 +1,147 @@
+package api
+
+import (
+	"first-app/todo_go/db/migration"
+	"first-app/todo_go/static"
+	"first-app/todo_go/templates"
+	"first-app/todo_go/util"
+	"io/fs"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// Option configures the Server built by NewServer.
+type Option func(*serverOptions)
+
+type serverOptions struct {
+	templateFS        fs.FS
+	templatePatterns  []string
+	templateReload    bool
+	staticFS          fs.FS
+	migrations        fs.FS
+	secrets           Secrets
+	csrfIgnoreMethods []string
+	sessionStore      sessions.Store
+	middleware        []gin.HandlerFunc
+	logger            *slog.Logger
+	now               func() time.Time
+	pageSize          int
+	maxPageSize       int
+}
+
+// defaultOptions serves the templates and assets embedded in the binary,
+// expects the schema of the MySQL migrations, signs with the development
+// secrets and shows 5 todos per page.
+func defaultOptions() serverOptions {
+	migrations, _ := migration.ForDriver("mysql")
+	return serverOptions{
+		templateFS:       templates.FS,
+		templatePatterns: []string{"*.html"},
+		staticFS:         static.FS,
+		migrations:       migrations,
+		secrets: Secrets{
+			SessionKeys: []string{util.DefaultSessionKey},
+			CSRFSecret:  util.DefaultCSRFSecret,
+		},
+		now:         time.Now,
+		pageSize:    5,
+		maxPageSize: 100,
+	}
+}
+
+// WithTemplates loads the HTML templates matching patterns from fsys.
+func WithTemplates(fsys fs.FS, patterns ...string) Option {
+	return func(o *serverOptions) {
+		o.templateFS = fsys
+		o.templatePatterns = patterns
+		o.templateReload = false
+	}
+}
+
+// WithTemplateDir loads the HTML templates from dir on disk instead of the
+// embedded ones, and parses them again on every render so that they can be
+// edited without restarting the server.
+func WithTemplateDir(dir string) Option {
+	return func(o *serverOptions) {
+		o.templateFS = os.DirFS(dir)
+		o.templatePatterns = []string{"*.html"}
+		o.templateReload = true
+	}
+}
+
+// WithMigrations sets the migrations readyz expects the database schema to be
+// at, the ones of the DB_DRIVER the repo runs on.
+func WithMigrations(fsys fs.FS) Option {
+	return func(o *serverOptions) {
+		o.migrations = fsys
+	}
+}
+
+// WithSecrets sets the keys the session cookies and CSRF tokens are signed
+// with. Without it the server uses the public development defaults.
+func WithSecrets(secrets Secrets) Option {
+	return func(o *serverOptions) {
+		o.secrets = secrets
+	}
+}
+
+// WithCSRFIgnoreMethods sets the HTTP methods the CSRF check lets through.
+// By default only GET, HEAD and OPTIONS are.
+func WithCSRFIgnoreMethods(methods ...string) Option {
+	return func(o *serverOptions) {
+		o.csrfIgnoreMethods = methods
+	}
+}
+
+// WithSessionStore keeps the sessions in store instead of cookies signed with
+// the session keys.
+func WithSessionStore(store sessions.Store) Option {
+	return func(o *serverOptions) {
+		o.sessionStore = store
+	}
+}
+
+// WithMiddleware runs handlers on every request, after the request ID,
+// logging, metrics and recovery middleware and before the sessions.
+func WithMiddleware(handlers ...gin.HandlerFunc) Option {
+	return func(o *serverOptions) {
+		o.middleware = append(o.middleware, handlers...)
+	}
+}
+
+// WithLogger sets the logger the request loggers are derived from. By
+// default it is slog.Default at the time of the request.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *serverOptions) {
+		o.logger = logger
+	}
+}
+
+// WithClock sets the function the server reads the current time from, like
+// when checking if an access token has expired.
+func WithClock(now func() time.Time) Option {
+	return func(o *serverOptions) {
+		o.now = now
+	}
+}
+
+// WithPageSize sets how many todos the lists show per page and the largest
+// page a client may ask for with per_page or limit. Sizes below one keep the
+// defaults of 5 and 100, and max is raised to size when it is smaller.
+func WithPageSize(size, max int) Option {
+	return func(o *serverOptions) {
+		if size > 0 {
+			o.pageSize = size
+		}
+		if max > 0 {
+			o.maxPageSize = max
+		}
+		if o.maxPageSize < o.pageSize {
+			o.maxPageSize = o.pageSize
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	mockdb "first-app/todo_go/db/mock"
+	"first-app/todo_go/util"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerOptions(t *testing.T) {
+	testCases := []struct {
+		name          string
+		options       func(t *testing.T) []Option
+		method        string
+		targetUrl     string
+		checkResponse func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Templates",
+			options: func(t *testing.T) []Option {
+				templates := fstest.MapFS{
+					"login.html": {Data: []byte(`custom login {{ .token }}`)},
+				}
+				return []Option{WithTemplates(templates, "*.html")}
+			},
+			method:    http.MethodGet,
+			targetUrl: "/login",
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "custom login ")
+			},
+		},
+		{
+			name: "CSRF Enforced By Default",
+			options: func(t *testing.T) []Option {
+				return nil
+			},
+			method:    http.MethodPost,
+			targetUrl: "/login",
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, "CSRF token mismatch", recorder.Body.String())
+			},
+		},
+		{
+			name: "Middleware",
+			options: func(t *testing.T) []Option {
+				return []Option{WithMiddleware(func(ctx *gin.Context) {
+					ctx.Header("X-Custom", "yes")
+				})}
+			},
+			method:    http.MethodGet,
+			targetUrl: "/livez",
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "yes", recorder.Header().Get("X-Custom"))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := NewServer(mockdb.NewMockRepo(ctrl), tc.options(t)...)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.targetUrl, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, server, recorder)
+		})
+	}
+}
+
+func TestWithSessionStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := cookie.NewStore([]byte("custom-store"))
+	server := NewServer(mockdb.NewMockRepo(ctrl), WithSessionStore(store), WithSecrets(testSecrets))
+	require.Equal(t, store, server.sessionStore)
+}
+
+func TestWithLogger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil)).With(slog.String("service", "todo"))
+	server := NewServer(mockdb.NewMockRepo(ctrl), WithLogger(logger))
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/livez", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	entries := decodeLogs(t, &buf)
+	require.Len(t, entries, 1)
+	require.Equal(t, "todo", entries[0]["service"])
+	require.Equal(t, "/livez", entries[0]["route"])
+}
+
+func TestWithClock(t *testing.T) {
+	user := randomUser(t)
+	plainToken, err := util.GenerateAccessToken()
+	require.NoError(t, err)
+	tokenHash := util.HashAccessToken(plainToken)
+	expiresAt := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		now        time.Time
+		wantStatus int
+	}{
+		{
+			name:       "Before Expiry",
+			now:        expiresAt.Add(-time.Minute),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "After Expiry",
+			now:        expiresAt.Add(time.Minute),
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			token := randomAccessToken(user, tokenHash, scopeRead)
+			token.ExpiresAt = sql.NullTime{Time: expiresAt, Valid: true}
+
+			repo := mockdb.NewMockRepo(ctrl)
+			repo.EXPECT().
+				GetAccessTokenByHash(gomock.Any(), gomock.Eq(tokenHash)).
+				Times(1).
+				Return(token, nil)
+			repo.EXPECT().
+				TouchAccessToken(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				Return(nil)
+			repo.EXPECT().
+				ListTags(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				Return(nil, nil)
+
+			server := NewServer(repo, WithClock(func() time.Time { return tc.now }))
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/api/v1/tags", nil)
+			require.NoError(t, err)
+			addBearerToken(request, plainToken)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.wantStatus, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// navLen is how many page links the numbered lists show at once.
+const navLen = 5
+
+// perPageSizes are the page sizes the lists offer, up to the max of the
+// server.
+var perPageSizes = []int{5, 10, 20, 50, 100}
+
+var errInvalidPerPage = errors.New("per_page must be a positive integer")
+
+// pageParams reads the page and per_page params of the numbered lists. The
+// page is not checked against the list, see util.GetPageInfo. per_page
+// defaults to the page size of the server and is capped at its max.
+func (server *Server) pageParams(ctx *gin.Context) (page, perPage int, err error) {
+	page, err = strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	perPage = server.pageSize
+	if value, ok := ctx.GetQuery("per_page"); ok {
+		perPage, err = strconv.Atoi(value)
+		if err != nil || perPage < 1 {
+			return 0, 0, errInvalidPerPage
+		}
+		perPage = min(perPage, server.maxPageSize)
+	}
+	return page, perPage, nil
+}
+
+// perPageLink returns the query string that keeps perPage on the links of a
+// list, empty for the default size.
+func (server *Server) perPageLink(perPage int) string {
+	if perPage == server.pageSize {
+		return ""
+	}
+	return fmt.Sprintf("&per_page=%d", perPage)
+}
+
+// perPageOption is a page size to pick on a list.
+type perPageOption struct {
+	Size       int
+	Link       string
+	IsSelected bool
+}
+
+// perPageOptions returns the page sizes to offer on the list at path, linking
+// back to its first page with the query string link appended.
+func (server *Server) perPageOptions(path string, perPage int, link string) []perPageOption {
+	var options []perPageOption
+	add := func(size int) {
+		options = append(options, perPageOption{
+			Size:       size,
+			Link:       fmt.Sprintf("%s?per_page=%d%s", path, size, link),
+			IsSelected: size == perPage,
+		})
+	}
+
+	added := false
+	for _, size := range perPageSizes {
+		if size > server.maxPageSize {
+			break
+		}
+		if !added && server.pageSize <= size {
+			if server.pageSize < size {
+				add(server.pageSize)
+			}
+			added = true
+		}
+		add(size)
+	}
+	if !added {
+		add(server.pageSize)
+	}
+	return options
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPageSize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		size     int
+		max      int
+		wantSize int
+		wantMax  int
+	}{
+		{name: "Sizes", size: 10, max: 50, wantSize: 10, wantMax: 50},
+		{name: "Defaults", size: 0, max: -1, wantSize: 5, wantMax: 100},
+		{name: "Max Below Size", size: 20, max: 10, wantSize: 20, wantMax: 20},
+		{name: "Size Above Default Max", size: 200, max: 0, wantSize: 200, wantMax: 200},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer(nil, WithPageSize(tc.size, tc.max))
+			require.Equal(t, tc.wantSize, server.pageSize)
+			require.Equal(t, tc.wantMax, server.maxPageSize)
+		})
+	}
+}
+
+func TestPerPageOptions(t *testing.T) {
+	testCases := []struct {
+		name      string
+		size      int
+		max       int
+		wantSizes []int
+	}{
+		{name: "Defaults", size: 5, max: 100, wantSizes: []int{5, 10, 20, 50, 100}},
+		{name: "Capped", size: 5, max: 30, wantSizes: []int{5, 10, 20}},
+		{name: "Uncommon Size", size: 15, max: 50, wantSizes: []int{5, 10, 15, 20, 50}},
+		{name: "Size At Max", size: 30, max: 30, wantSizes: []int{5, 10, 20, 30}},
+		{name: "Size Above All", size: 200, max: 300, wantSizes: []int{5, 10, 20, 50, 100, 200}},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer(nil, WithPageSize(tc.size, tc.max))
+			options := server.perPageOptions("/index", 10, "&q=dog")
+
+			var sizes []int
+			for _, option := range options {
+				sizes = append(sizes, option.Size)
+				require.Equal(t, option.Size == 10, option.IsSelected)
+			}
+			require.Equal(t, tc.wantSizes, sizes)
+			require.Equal(t, "/index?per_page=5&q=dog", options[0].Link)
+		})
+	}
+}
@@ -1,7 +1,16 @@
 package api
 
 import (
+	"context"
+	"errors"
 	db "first-app/todo_go/db/sqlc"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/cookie"
@@ -10,57 +19,186 @@ import (
 )
 
 type Server struct {
-	repo   db.Repo
-	router *gin.Engine
+	repo         db.Repo
+	router       *gin.Engine
+	sessionStore sessions.Store
+	logger       *slog.Logger
+	now          func() time.Time
+	migrations   fs.FS
+	pageSize     int
+	maxPageSize  int
 }
 
-func NewServer(repo db.Repo, isTest bool) *Server {
-	server := &Server{repo: repo}
+// Secrets are the keys the server signs its cookies and tokens with.
+type Secrets struct {
+	// SessionKeys sign the session cookies. The first key signs new sessions
+	// and every key is tried when verifying one, which lets keys be rotated.
+	SessionKeys []string
+	CSRFSecret  string
+}
+
+// sessionKeyPairs turns the session keys into the hash and encryption key
+// pairs of the cookie store. The sessions are signed, not encrypted.
+func (secrets Secrets) sessionKeyPairs() [][]byte {
+	pairs := make([][]byte, 0, 2*len(secrets.SessionKeys))
+	for _, key := range secrets.SessionKeys {
+		pairs = append(pairs, []byte(key), nil)
+	}
+	return pairs
+}
 
-	server.setupRouter(isTest)
+// NewServer builds the server for repo. It panics when the templates cannot be
+// parsed, like gin does when loading them.
+func NewServer(repo db.Repo, opts ...Option) *Server {
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	server := &Server{
+		repo:         repo,
+		sessionStore: options.sessionStore,
+		logger:       options.logger,
+		now:          options.now,
+		migrations:   options.migrations,
+		pageSize:     options.pageSize,
+		maxPageSize:  options.maxPageSize,
+	}
+	if server.sessionStore == nil {
+		server.sessionStore = cookie.NewStore(options.secrets.sessionKeyPairs()...)
+	}
+
+	server.setupRouter(options)
 	return server
 }
 
-func (server *Server) setupRouter(isTest bool) {
-	router := gin.Default()
-
-	store := cookie.NewStore([]byte("secret"))
-	router.Use(sessions.Sessions("mysession", store))
-	var options csrf.Options
-	if isTest {
-		options = csrf.Options{
-			Secret:        "secret123",
-			IgnoreMethods: []string{"GET", "POST"},
-			ErrorFunc: func(c *gin.Context) {
-				c.String(400, "CSRF token mismatch")
-				c.Abort()
-			},
+func (server *Server) setupRouter(options serverOptions) {
+	router := gin.New()
+	// Let the request context, with its request ID and cancellation, reach
+	// db.Repo through the *gin.Context the handlers pass down.
+	router.ContextWithFallback = true
+	router.Use(requestID(), requestLogger(server.logger), accessLog(), metrics(), gin.Recovery())
+	router.Use(options.middleware...)
+
+	router.Use(sessions.Sessions(sessionName, server.sessionStore))
+	csrfProtect := csrfMiddleware(csrf.Options{
+		Secret:        options.secrets.CSRFSecret,
+		IgnoreMethods: options.csrfIgnoreMethods,
+		ErrorFunc: func(c *gin.Context) {
+			c.String(400, "CSRF token mismatch")
+			c.Abort()
+		},
+	})
+	if options.templateReload {
+		router.HTMLRender = reloadingRender{
+			fsys:     options.templateFS,
+			patterns: options.templatePatterns,
+			funcs:    router.FuncMap,
 		}
 	} else {
-		options = csrf.Options{
-			Secret: "secret123",
-			ErrorFunc: func(c *gin.Context) {
-				c.String(400, "CSRF token mismatch")
-				c.Abort()
-			},
-		}
+		router.SetHTMLTemplate(template.Must(parseTemplates(options.templateFS, router.FuncMap, options.templatePatterns...)))
 	}
-	router.Use(csrf.Middleware(options))
-	router.LoadHTMLGlob("../templates/*")
-	// router.LoadHTMLFiles("../templates/edit.html", "../templates/index.html", "../templates/new.html", "../templates/show.html")
+	serveStatic(router, options.staticFS)
 
 	router.GET("/health", server.healthGet)
-	router.GET("/index", server.listUpTodo)
-	router.GET("/new", server.newTodo)
-	router.POST("/new", server.createTodo)
-	router.GET("/show", server.showTodo)
-	router.GET("/edit", server.editTodo)
-	router.POST("/edit", server.updateTodo)
-	router.POST("/delete", server.deleteTodo)
+	router.GET("/livez", server.livez)
+	router.GET("/readyz", server.readyz)
+	router.GET("/metrics", metricsHandler())
+
+	web := router.Group("/", csrfProtect)
+	web.GET("/signup", server.signupPage)
+	web.POST("/signup", server.signup)
+	web.GET("/login", server.loginPage)
+	web.POST("/login", server.login)
+	web.POST("/logout", server.logout)
+
+	authRoutes := web.Group("/", authMiddleware(redirectToLogin))
+	authRoutes.GET("/index", server.listUpTodo)
+	authRoutes.GET("/new", server.newTodo)
+	authRoutes.POST("/new", server.createTodo)
+	authRoutes.GET("/show", server.showTodo)
+	authRoutes.GET("/edit", server.editTodo)
+	authRoutes.POST("/edit", server.updateTodo)
+	authRoutes.POST("/toggle", server.toggleTodo)
+	authRoutes.POST("/delete", server.deleteTodo)
+	authRoutes.GET("/trash", server.listTrash)
+	authRoutes.POST("/trash/restore", server.restoreTodo)
+	authRoutes.POST("/trash/purge", server.purgeTodo)
+	authRoutes.POST("/tags/attach", server.attachTag)
+	authRoutes.POST("/tags/detach", server.detachTag)
+	authRoutes.GET("/tokens", server.listTokens)
+	authRoutes.POST("/tokens", server.createToken)
+	authRoutes.POST("/tokens/revoke", server.revokeToken)
+
+	// Requests authenticated by a personal access token skip the CSRF check,
+	// so tokenAuthMiddleware has to run before csrfProtect. Checking the login
+	// first answers clients without any credentials with 401, not a CSRF error.
+	v1 := router.Group("/api/v1", server.tokenAuthMiddleware(), authMiddleware(unauthorizedJSON), csrfProtect)
+	v1.GET("/todos", server.listTodoJSON)
+	v1.POST("/todos", server.createTodoJSON)
+	v1.POST("/todos/bulk-delete", server.deleteTodoListJSON)
+	v1.GET("/todos/:id", server.getTodoJSON)
+	v1.PUT("/todos/:id", server.updateTodoJSON)
+	v1.PATCH("/todos/:id", server.patchTodoJSON)
+	v1.DELETE("/todos/:id", server.deleteTodoJSON)
+	v1.POST("/todos/:id/toggle", server.toggleTodoJSON)
+	v1.GET("/todos/:id/tags", server.listTodoTagsJSON)
+	v1.POST("/todos/:id/tags", server.attachTagJSON)
+	v1.PUT("/todos/:id/tags", server.replaceTodoTagsJSON)
+	v1.DELETE("/todos/:id/tags/:tagId", server.detachTagJSON)
+	v1.GET("/tags", server.listTagsJSON)
 
 	server.router = router
 }
 
-func (server *Server) Start(address string) error {
-	return server.router.Run(address)
+// HTTPTimeouts limits how long the server waits on clients. Shutdown is the
+// drain period in-flight requests get to finish once the server is stopped.
+type HTTPTimeouts struct {
+	Read     time.Duration
+	Write    time.Duration
+	Idle     time.Duration
+	Shutdown time.Duration
+}
+
+// Start serves on address until ctx is done, then stops taking new
+// connections and waits for the in-flight requests to finish.
+func (server *Server) Start(ctx context.Context, address string, timeouts HTTPTimeouts) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return server.serve(ctx, listener, timeouts)
+}
+
+func (server *Server) serve(ctx context.Context, listener net.Listener, timeouts HTTPTimeouts) error {
+	httpServer := &http.Server{
+		Handler:           server.router,
+		ReadTimeout:       timeouts.Read,
+		ReadHeaderTimeout: timeouts.Read,
+		WriteTimeout:      timeouts.Write,
+		IdleTimeout:       timeouts.Idle,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+	slog.Info("Server started", slog.String("address", listener.Addr().String()))
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Server shutting down", slog.Duration("drain", timeouts.Shutdown))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeouts.Shutdown)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("cannot drain in-flight requests: %w", err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
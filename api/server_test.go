@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	mockdb "first-app/todo_go/db/mock"
+	db "first-app/todo_go/db/sqlc"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeGracefulShutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(mockdb.NewMockRepo(ctrl))
+	started := make(chan struct{})
+	server.router.GET("/slow", func(ctx *gin.Context) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		ctx.String(http.StatusOK, "done")
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.serve(ctx, listener, HTTPTimeouts{Shutdown: 5 * time.Second})
+	}()
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		res, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		response <- result{body: string(body), err: err}
+	}()
+
+	<-started
+	cancel()
+
+	// The request in flight when the shutdown starts still gets its response.
+	res := <-response
+	require.NoError(t, res.err)
+	require.Equal(t, "done", res.body)
+	require.NoError(t, <-serveErr)
+
+	_, err = http.Get("http://" + listener.Addr().String() + "/livez")
+	require.Error(t, err)
+}
+
+func TestServeShutdownTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(mockdb.NewMockRepo(ctrl))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	server.router.GET("/stuck", func(ctx *gin.Context) {
+		close(started)
+		<-release
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.serve(ctx, listener, HTTPTimeouts{Shutdown: 50 * time.Millisecond})
+	}()
+	go http.Get("http://" + listener.Addr().String() + "/stuck")
+
+	<-started
+	cancel()
+	require.ErrorIs(t, <-serveErr, context.DeadlineExceeded)
+}
+
+func TestSessionKeyRotation(t *testing.T) {
+	user := randomUser(t)
+	csrfSecret := testSecrets.CSRFSecret
+
+	testCases := []struct {
+		name       string
+		signKeys   []string
+		verifyKeys []string
+		wantStatus int
+	}{
+		{
+			name:       "Same Key",
+			signKeys:   []string{"old-key"},
+			verifyKeys: []string{"old-key"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Old Key Kept After Rotation",
+			signKeys:   []string{"old-key"},
+			verifyKeys: []string{"new-key", "old-key"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Signed With New Key",
+			signKeys:   []string{"new-key", "old-key"},
+			verifyKeys: []string{"new-key"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Old Key Dropped",
+			signKeys:   []string{"old-key"},
+			verifyKeys: []string{"new-key"},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			repo.EXPECT().
+				ListTags(gomock.Any(), gomock.Eq(user.ID)).
+				AnyTimes().
+				Return([]db.Tag{}, nil)
+
+			signer := NewServer(repo, WithSecrets(Secrets{SessionKeys: tc.signKeys, CSRFSecret: csrfSecret}))
+			server := NewServer(repo, WithSecrets(Secrets{SessionKeys: tc.verifyKeys, CSRFSecret: csrfSecret}))
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/api/v1/tags", nil)
+			require.NoError(t, err)
+
+			addAuthSession(t, signer, request, user)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.wantStatus, recorder.Code)
+		})
+	}
+}
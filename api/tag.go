@@ -0,0 +1,402 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	db "first-app/todo_go/db/sqlc"
+	"first-app/todo_go/util"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTagNameLen is the length of the name column of the tags table, in
+// characters rather than bytes.
+const maxTagNameLen = 64
+
+var errInvalidTagName = errors.New("tag names must not be blank or longer than 64 characters")
+
+// normalizeTagNames trims the names and drops duplicates, keeping the order.
+func normalizeTagNames(names []string) ([]string, error) {
+	normalized := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 || utf8.RuneCountInString(name) > maxTagNameLen {
+			return nil, errInvalidTagName
+		}
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized, nil
+}
+
+// tagNamesFromForm reads the comma separated tags input of the todo forms.
+func tagNamesFromForm(value string) ([]string, error) {
+	names := []string{}
+	for _, name := range strings.Split(value, ",") {
+		if len(strings.TrimSpace(name)) > 0 {
+			names = append(names, name)
+		}
+	}
+	return normalizeTagNames(names)
+}
+
+// findOrCreateTag returns the tag of the user with the given name, creating it
+// on first use.
+func (server *Server) findOrCreateTag(ctx *gin.Context, userID int64, name string) (db.Tag, error) {
+	arg := db.GetTagByNameParams{
+		UserID: userID,
+		Name:   name,
+	}
+	tag, err := server.repo.GetTagByName(ctx, arg)
+	if err != sql.ErrNoRows {
+		return tag, err
+	}
+
+	result, err := server.repo.CreateTag(ctx, db.CreateTagParams{
+		UserID: userID,
+		Name:   name,
+	})
+	if err != nil {
+		// Another request created the same tag in the meantime.
+		if errors.Is(err, db.ErrDuplicate) {
+			return server.repo.GetTagByName(ctx, arg)
+		}
+		return db.Tag{}, err
+	}
+	createdId, _ := result.LastInsertId()
+
+	return db.Tag{ID: createdId, UserID: userID, Name: name}, nil
+}
+
+type attachTagRequest struct {
+	ID   int64  `form:"id" binding:"required,min=1"`
+	Name string `form:"name" binding:"required,max=64"`
+}
+
+func (server *Server) attachTag(ctx *gin.Context) {
+	var req attachTagRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid request data",
+			Effect:   "User can see 400 Bad Request page",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "tag.go file, attachTag method",
+		}, err)
+		ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
+		return
+	}
+	addLogAttrs(ctx, slog.Int64("todo_id", req.ID))
+	name := strings.TrimSpace(req.Name)
+	if len(name) == 0 {
+		ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
+		return
+	}
+
+	user := currentUser(ctx)
+	todo, dbErr := server.repo.GetTodo(ctx, db.GetTodoParams{
+		ID:     req.ID,
+		UserID: user.ID,
+	})
+	if dbErr != nil {
+		if dbErr == sql.ErrNoRows {
+			ctx.HTML(http.StatusNotFound, "500.html", gin.H{})
+			return
+		}
+
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the data from DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "tag.go file, attachTag method on GetTodo query",
+		}, dbErr)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
+	tag, dbErr := server.findOrCreateTag(ctx, user.ID, name)
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching or inserting the tag on DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "tag.go file, attachTag method on GetTagByName or CreateTag query",
+		}, dbErr)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
+	arg := db.AttachTagParams{
+		TodoID: todo.ID,
+		TagID:  tag.ID,
+	}
+	if dbErr := server.repo.AttachTag(ctx, arg); dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when inserting the data to DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "tag.go file, attachTag method on AttachTag query",
+		}, dbErr)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, fmt.Sprintf("/show?id=%d", todo.ID))
+}
+
+type detachTagRequest struct {
+	ID    int64 `form:"id" binding:"required,min=1"`
+	TagID int64 `form:"tagId" binding:"required,min=1"`
+}
+
+func (server *Server) detachTag(ctx *gin.Context) {
+	var req detachTagRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid request data",
+			Effect:   "User can see 400 Bad Request page",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "tag.go file, detachTag method",
+		}, err)
+		ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
+		return
+	}
+	addLogAttrs(ctx, slog.Int64("todo_id", req.ID))
+
+	user := currentUser(ctx)
+	arg := db.DetachTagParams{
+		TodoID: req.ID,
+		TagID:  req.TagID,
+		UserID: user.ID,
+	}
+	if dbErr := server.repo.DetachTag(ctx, arg); dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when deleting the data on DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "tag.go file, detachTag method on DetachTag query",
+		}, dbErr)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, fmt.Sprintf("/show?id=%d", req.ID))
+}
+
+func (server *Server) listTagsJSON(ctx *gin.Context) {
+	user := currentUser(ctx)
+	tags, dbErr := server.repo.ListTags(ctx, user.ID)
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the data from DB",
+			Effect:   "User can see 500 Internal Server Error response",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "tag.go file, listTagsJSON method on ListTags query",
+		}, dbErr)
+		ctx.JSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to list tags"))
+		return
+	}
+	if tags == nil {
+		tags = []db.Tag{}
+	}
+
+	ctx.JSON(http.StatusOK, tags)
+}
+
+func (server *Server) listTodoTagsJSON(ctx *gin.Context) {
+	todo, ok := server.fetchTodoJSON(ctx, "tag.go file, listTodoTagsJSON method")
+	if !ok {
+		return
+	}
+
+	user := currentUser(ctx)
+	tags, dbErr := server.repo.ListTagsByTodo(ctx, db.ListTagsByTodoParams{
+		TodoID: todo.ID,
+		UserID: user.ID,
+	})
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the data from DB",
+			Effect:   "User can see 500 Internal Server Error response",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "tag.go file, listTodoTagsJSON method on ListTagsByTodo query",
+		}, dbErr)
+		ctx.JSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to list tags"))
+		return
+	}
+	if tags == nil {
+		tags = []db.Tag{}
+	}
+
+	ctx.JSON(http.StatusOK, tags)
+}
+
+type attachTagJSONRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+func (server *Server) attachTagJSON(ctx *gin.Context) {
+	todo, ok := server.fetchTodoJSON(ctx, "tag.go file, attachTagJSON method")
+	if !ok {
+		return
+	}
+
+	var req attachTagJSONRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid request data",
+			Effect:   "User can see 400 Bad Request response",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "tag.go file, attachTagJSON method",
+		}, err)
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid_request", err.Error()))
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if len(name) == 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid_request", "tag name must not be blank"))
+		return
+	}
+
+	user := currentUser(ctx)
+	tag, dbErr := server.findOrCreateTag(ctx, user.ID, name)
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching or inserting the tag on DB",
+			Effect:   "User can see 500 Internal Server Error response",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "tag.go file, attachTagJSON method on GetTagByName or CreateTag query",
+		}, dbErr)
+		ctx.JSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to create tag"))
+		return
+	}
+
+	arg := db.AttachTagParams{
+		TodoID: todo.ID,
+		TagID:  tag.ID,
+	}
+	if dbErr := server.repo.AttachTag(ctx, arg); dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when inserting the data to DB",
+			Effect:   "User can see 500 Internal Server Error response",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "tag.go file, attachTagJSON method on AttachTag query",
+		}, dbErr)
+		ctx.JSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to attach tag"))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, tag)
+}
+
+type detachTagURIRequest struct {
+	TagID int64 `uri:"tagId" binding:"min=1"`
+}
+
+func (server *Server) detachTagJSON(ctx *gin.Context) {
+	todo, ok := server.fetchTodoJSON(ctx, "tag.go file, detachTagJSON method")
+	if !ok {
+		return
+	}
+
+	var req detachTagURIRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid tag ID param",
+			Effect:   "User can see 400 Bad Request response",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "tag.go file, detachTagJSON method",
+		}, err)
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid_request", err.Error()))
+		return
+	}
+
+	user := currentUser(ctx)
+	arg := db.DetachTagParams{
+		TodoID: todo.ID,
+		TagID:  req.TagID,
+		UserID: user.ID,
+	}
+	if dbErr := server.repo.DetachTag(ctx, arg); dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when deleting the data on DB",
+			Effect:   "User can see 500 Internal Server Error response",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "tag.go file, detachTagJSON method on DetachTag query",
+		}, dbErr)
+		ctx.JSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to detach tag"))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type replaceTodoTagsJSONRequest struct {
+	Names []string `json:"names" binding:"required,max=20"`
+}
+
+func (server *Server) replaceTodoTagsJSON(ctx *gin.Context) {
+	var uri todoURIRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid ID param",
+			Effect:   "User can see 400 Bad Request response",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "tag.go file, replaceTodoTagsJSON method",
+		}, err)
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid_request", err.Error()))
+		return
+	}
+	addLogAttrs(ctx, slog.Int64("todo_id", uri.ID))
+
+	var req replaceTodoTagsJSONRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid request data",
+			Effect:   "User can see 400 Bad Request response",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "tag.go file, replaceTodoTagsJSON method",
+		}, err)
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid_request", err.Error()))
+		return
+	}
+	names, err := normalizeTagNames(req.Names)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid_request", err.Error()))
+		return
+	}
+
+	user := currentUser(ctx)
+	tags, dbErr := server.repo.ReplaceTodoTagsTx(ctx, db.ReplaceTodoTagsTxParams{
+		TodoID:   uri.ID,
+		UserID:   user.ID,
+		TagNames: names,
+	})
+	if dbErr != nil {
+		if dbErr == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse("not_found", "todo not found"))
+			return
+		}
+
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when replacing the tags on DB",
+			Effect:   "User can see 500 Internal Server Error response",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "tag.go file, replaceTodoTagsJSON method on ReplaceTodoTagsTx transaction",
+		}, dbErr)
+		ctx.JSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to replace tags"))
+		return
+	}
+	if tags == nil {
+		tags = []db.Tag{}
+	}
+
+	ctx.JSON(http.StatusOK, tags)
+}
@@ -0,0 +1,831 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	mockdb "first-app/todo_go/db/mock"
+	db "first-app/todo_go/db/sqlc"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachTagAPI(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+	tag := db.Tag{ID: 1, UserID: user.ID, Name: "project-x"}
+
+	testCases := []struct {
+		name          string
+		todoID        int64
+		tagName       string
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK with existing tag",
+			todoID:  todo.ID,
+			tagName: " project-x ",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					GetTagByName(gomock.Any(), gomock.Eq(db.GetTagByNameParams{UserID: user.ID, Name: tag.Name})).
+					Times(1).
+					Return(tag, nil)
+
+				repo.EXPECT().
+					CreateTag(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				repo.EXPECT().
+					AttachTag(gomock.Any(), gomock.Eq(db.AttachTagParams{TodoID: todo.ID, TagID: tag.ID})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+				require.Equal(t, fmt.Sprintf("/show?id=%d", todo.ID), recorder.Header().Get("Location"))
+			},
+		},
+		{
+			name:    "OK with new tag",
+			todoID:  todo.ID,
+			tagName: tag.Name,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					GetTagByName(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Tag{}, sql.ErrNoRows)
+
+				repo.EXPECT().
+					CreateTag(gomock.Any(), gomock.Eq(db.CreateTagParams{UserID: user.ID, Name: tag.Name})).
+					Times(1).
+					Return(MockSqlReturn{}, nil)
+
+				repo.EXPECT().
+					AttachTag(gomock.Any(), gomock.Eq(db.AttachTagParams{TodoID: todo.ID, TagID: 1})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+			},
+		},
+		{
+			name:    "OK with concurrently created tag",
+			todoID:  todo.ID,
+			tagName: tag.Name,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(todo, nil)
+
+				gomock.InOrder(
+					repo.EXPECT().
+						GetTagByName(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.Tag{}, sql.ErrNoRows),
+					repo.EXPECT().
+						CreateTag(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(MockSqlReturn{}, db.ErrDuplicate),
+					repo.EXPECT().
+						GetTagByName(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(tag, nil),
+				)
+
+				repo.EXPECT().
+					AttachTag(gomock.Any(), gomock.Eq(db.AttachTagParams{TodoID: todo.ID, TagID: tag.ID})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+			},
+		},
+		{
+			name:    "Blank Name",
+			todoID:  todo.ID,
+			tagName: "   ",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "Too Long Name",
+			todoID:  todo.ID,
+			tagName: strings.Repeat("a", 65),
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "Todo Not Found",
+			todoID:  todo.ID,
+			tagName: tag.Name,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Todo{}, sql.ErrNoRows)
+
+				repo.EXPECT().
+					AttachTag(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:    "Internal Error",
+			todoID:  todo.ID,
+			tagName: tag.Name,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					GetTagByName(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Tag{}, sql.ErrConnDone)
+
+				repo.EXPECT().
+					AttachTag(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			data := url.Values{}
+			data.Set("id", fmt.Sprintf("%d", tc.todoID))
+			data.Set("name", tc.tagName)
+
+			targetUrl := "/tags/attach"
+			request, err := http.NewRequest(http.MethodPost, targetUrl, strings.NewReader(data.Encode()))
+			request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDetachTagAPI(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+
+	testCases := []struct {
+		name          string
+		tagID         int64
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			tagID: 3,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				arg := db.DetachTagParams{
+					TodoID: todo.ID,
+					TagID:  3,
+					UserID: user.ID,
+				}
+
+				repo.EXPECT().
+					DetachTag(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+				require.Equal(t, fmt.Sprintf("/show?id=%d", todo.ID), recorder.Header().Get("Location"))
+			},
+		},
+		{
+			name:  "Bad Request",
+			tagID: 0,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					DetachTag(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "Internal Error",
+			tagID: 3,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					DetachTag(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			data := url.Values{}
+			data.Set("id", fmt.Sprintf("%d", todo.ID))
+			data.Set("tagId", fmt.Sprintf("%d", tc.tagID))
+
+			targetUrl := "/tags/detach"
+			request, err := http.NewRequest(http.MethodPost, targetUrl, strings.NewReader(data.Encode()))
+			request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListTagsJSON(t *testing.T) {
+	user := randomUser(t)
+	tags := []db.Tag{
+		{ID: 1, UserID: user.ID, Name: "home"},
+		{ID: 2, UserID: user.ID, Name: "work"},
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					ListTags(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(tags, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []db.Tag
+				requireBodyDecodes(t, recorder.Body, &got)
+				require.Equal(t, tags, got)
+			},
+		},
+		{
+			name: "OK with no tags",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					ListTags(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(nil, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, "[]", recorder.Body.String())
+			},
+		},
+		{
+			name: "Internal Error",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					ListTags(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder.Body, "internal_error")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/api/v1/tags", nil)
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListTodoTagsJSON(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+	tags := []db.Tag{{ID: 1, UserID: user.ID, Name: "home"}}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					ListTagsByTodo(gomock.Any(), gomock.Eq(db.ListTagsByTodoParams{TodoID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(tags, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []db.Tag
+				requireBodyDecodes(t, recorder.Body, &got)
+				require.Equal(t, tags, got)
+			},
+		},
+		{
+			name: "Not Found",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Todo{}, sql.ErrNoRows)
+
+				repo.EXPECT().
+					ListTagsByTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder.Body, "not_found")
+			},
+		},
+		{
+			name: "Internal Error",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					ListTagsByTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder.Body, "internal_error")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			targetUrl := fmt.Sprintf("/api/v1/todos/%d/tags", todo.ID)
+			request, err := http.NewRequest(http.MethodGet, targetUrl, nil)
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAttachTagJSON(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+	tag := db.Tag{ID: 4, UserID: user.ID, Name: "urgent"}
+
+	testCases := []struct {
+		name          string
+		body          interface{}
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]interface{}{"name": "urgent"},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					GetTagByName(gomock.Any(), gomock.Eq(db.GetTagByNameParams{UserID: user.ID, Name: tag.Name})).
+					Times(1).
+					Return(tag, nil)
+
+				repo.EXPECT().
+					AttachTag(gomock.Any(), gomock.Eq(db.AttachTagParams{TodoID: todo.ID, TagID: tag.ID})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got db.Tag
+				requireBodyDecodes(t, recorder.Body, &got)
+				require.Equal(t, tag, got)
+			},
+		},
+		{
+			name: "Blank Name",
+			body: map[string]interface{}{"name": "  "},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					GetTagByName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name: "Internal Error",
+			body: map[string]interface{}{"name": "urgent"},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					GetTagByName(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(tag, nil)
+
+				repo.EXPECT().
+					AttachTag(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder.Body, "internal_error")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			targetUrl := fmt.Sprintf("/api/v1/todos/%d/tags", todo.ID)
+			request, err := http.NewRequest(http.MethodPost, targetUrl, bytes.NewReader(body))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDetachTagJSON(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+
+	testCases := []struct {
+		name          string
+		tagID         string
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			tagID: "4",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(todo, nil)
+
+				arg := db.DetachTagParams{
+					TodoID: todo.ID,
+					TagID:  4,
+					UserID: user.ID,
+				}
+				repo.EXPECT().
+					DetachTag(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:  "Bad Request",
+			tagID: "abc",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					DetachTag(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name:  "Internal Error",
+			tagID: "4",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					DetachTag(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder.Body, "internal_error")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			targetUrl := fmt.Sprintf("/api/v1/todos/%d/tags/%s", todo.ID, tc.tagID)
+			request, err := http.NewRequest(http.MethodDelete, targetUrl, nil)
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestReplaceTodoTagsJSON(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+	tags := []db.Tag{
+		{ID: 1, UserID: user.ID, Name: "home"},
+		{ID: 2, UserID: user.ID, Name: "work"},
+	}
+
+	testCases := []struct {
+		name          string
+		body          interface{}
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]interface{}{"names": []string{"work", "home ", "work"}},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				arg := db.ReplaceTodoTagsTxParams{
+					TodoID:   todo.ID,
+					UserID:   user.ID,
+					TagNames: []string{"work", "home"},
+				}
+				repo.EXPECT().
+					ReplaceTodoTagsTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(tags, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []db.Tag
+				requireBodyDecodes(t, recorder.Body, &got)
+				require.Equal(t, tags, got)
+			},
+		},
+		{
+			name: "OK clearing tags",
+			body: map[string]interface{}{"names": []string{}},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				arg := db.ReplaceTodoTagsTxParams{
+					TodoID:   todo.ID,
+					UserID:   user.ID,
+					TagNames: []string{},
+				}
+				repo.EXPECT().
+					ReplaceTodoTagsTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(nil, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, "[]", recorder.Body.String())
+			},
+		},
+		{
+			name: "Blank Name",
+			body: map[string]interface{}{"names": []string{"work", ""}},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					ReplaceTodoTagsTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name: "Not Found",
+			body: map[string]interface{}{"names": []string{"work"}},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					ReplaceTodoTagsTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder.Body, "not_found")
+			},
+		},
+		{
+			name: "Internal Error",
+			body: map[string]interface{}{"names": []string{"work"}},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					ReplaceTodoTagsTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder.Body, "internal_error")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			targetUrl := fmt.Sprintf("/api/v1/todos/%d/tags", todo.ID)
+			request, err := http.NewRequest(http.MethodPut, targetUrl, bytes.NewReader(body))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestTagNamesFromForm(t *testing.T) {
+	names, err := tagNamesFromForm("")
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	names, err = tagNamesFromForm(" work,, urgent , work")
+	require.NoError(t, err)
+	require.Equal(t, []string{"work", "urgent"}, names)
+
+	_, err = tagNamesFromForm("work, " + strings.Repeat("a", 65))
+	require.ErrorIs(t, err, errInvalidTagName)
+
+	// 64 characters of three bytes each still fit the column.
+	long := strings.Repeat("日", 64)
+	names, err = tagNamesFromForm(long)
+	require.NoError(t, err)
+	require.Equal(t, []string{long}, names)
+
+	_, err = tagNamesFromForm(long + "本")
+	require.ErrorIs(t, err, errInvalidTagName)
+}
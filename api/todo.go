@@ -5,9 +5,11 @@ import (
 	db "first-app/todo_go/db/sqlc"
 	"first-app/todo_go/util"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	csrf "github.com/utrack/gin-csrf"
@@ -18,59 +20,100 @@ func (server *Server) healthGet(ctx *gin.Context) {
 }
 
 func (server *Server) listUpTodo(ctx *gin.Context) {
-	parsedPage := ctx.DefaultQuery("page", "1")
-	page, err := strconv.Atoi(parsedPage)
+	page, perPage, err := server.pageParams(ctx)
 	if err != nil {
-		errDetails := util.SetErrorDetails(
-			"User manipulated invalid page param",
-			"User can see 400 Bad Request page",
-			"Request param tempered by client, no need to special issue handling",
-			"todo.go file, listupTodo method",
-		)
-		log.Println(errDetails, err)
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid page or per_page param",
+			Effect:   "User can see 400 Bad Request page",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "todo.go file, listupTodo method",
+		}, err)
 		ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
 		return
 	}
-	limit := 5
-	navLen := 5
 
-	total, dbErr := server.repo.CountTodo(ctx)
+	var filter listTodoFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid sort or filter param",
+			Effect:   "User can see 400 Bad Request page",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "todo.go file, listupTodo method",
+		}, err)
+		ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
+		return
+	}
+
+	user := currentUser(ctx)
+	total, dbErr := server.repo.CountTodo(ctx, filter.todoFilter(user.ID))
 	if dbErr != nil {
-		errDetails := util.SetErrorDetails(
-			"Error occurred when fetching the data from DB",
-			"User can see 500 Internal Server Error page",
-			"This might be the database connection issue, please check the database status",
-			"todo.go file, listupTodo method on CountTodo query",
-		)
-		log.Println(errDetails, dbErr)
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the data from DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo.go file, listupTodo method on CountTodo query",
+		}, dbErr)
 		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
 		return
 	}
 
-	pageInfo := util.GetPageInfo(page, navLen, total, int64(limit))
+	pageLink := filter.pageLink() + server.perPageLink(perPage)
+	pageInfo := util.GetPageInfo(page, navLen, total, int64(perPage))
+	if pageInfo.Page != page {
+		ctx.Redirect(http.StatusFound, fmt.Sprintf("/index?page=%d%s", pageInfo.Page, pageLink))
+		return
+	}
+
+	todoList, dbErr := server.repo.ListTodo(ctx, filter.listTodoParams(user.ID, int32((page-1)*perPage), int32(perPage)))
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the data from DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo.go file, listupTodo method on ListTodo query",
+		}, dbErr)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
 
-	arg := db.ListTodoParams{
-		Offset: int32((page - 1) * limit),
-		Limit:  int32(limit),
+	todoIDs := make([]int64, len(todoList))
+	for i, todo := range todoList {
+		todoIDs[i] = todo.ID
 	}
-	todoList, dbErr := server.repo.ListTodo(ctx, arg)
+	tags, dbErr := server.repo.ListTagsByTodoIDs(ctx, db.ListTagsByTodoIDsParams{
+		UserID:  user.ID,
+		TodoIDs: todoIDs,
+	})
 	if dbErr != nil {
-		errDetails := util.SetErrorDetails(
-			"Error occurred when fetching the data from DB",
-			"User can see 500 Internal Server Error page",
-			"This might be the database connection issue, please check the database status",
-			"todo.go file, listupTodo method on ListTodo query",
-		)
-		log.Println(errDetails, dbErr)
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the data from DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo.go file, listupTodo method on ListTagsByTodoIDs query",
+		}, dbErr)
 		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
 		return
 	}
 
+	sortLinks := filter.sortLinks()
+	for field, link := range sortLinks {
+		sortLinks[field] = link + server.perPageLink(perPage)
+	}
+
 	ctx.HTML(http.StatusOK, "index.html", gin.H{
-		"title":    "Todo List",
-		"todoList": todoList,
-		"token":    csrf.GetToken(ctx),
-		"pageInfo": pageInfo,
+		"title":          "Todo List",
+		"flashes":        popFlashes(ctx),
+		"todoList":       todoList,
+		"tags":           tags,
+		"token":          csrf.GetToken(ctx),
+		"pageInfo":       pageInfo,
+		"pagePath":       "/index",
+		"perPage":        perPage,
+		"perPageOptions": server.perPageOptions("/index", perPage, filter.pageLink()),
+		"user":           user,
+		"filter":         filter,
+		"pageLink":       pageLink,
+		"sortLinks":      sortLinks,
 	})
 }
 
@@ -80,44 +123,79 @@ func (server *Server) newTodo(ctx *gin.Context) {
 	})
 }
 
+// dateLayout is the format of the date inputs on the forms and filters.
+const dateLayout = "2006-01-02"
+
+// dateFromForm converts an optional date input into a nullable column value.
+// The input is expected to be validated against dateLayout already.
+func dateFromForm(value string) sql.NullTime {
+	if len(value) == 0 {
+		return sql.NullTime{}
+	}
+	date, err := time.ParseInLocation(dateLayout, value, time.Local)
+	if err != nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: date, Valid: true}
+}
+
 type createTodoRequest struct {
-	Title       string `form:"titleInput" binding:"required"`
-	Description string `form:"descriptionInput" binding:"required"`
+	Title       string          `form:"titleInput" binding:"required"`
+	Description string          `form:"descriptionInput" binding:"required"`
+	Priority    db.TodoPriority `form:"priorityInput,default=medium" binding:"oneof=low medium high"`
+	DueAt       string          `form:"dueAtInput" binding:"omitempty,datetime=2006-01-02"`
+	Tags        string          `form:"tagsInput"`
 }
 
 func (server *Server) createTodo(ctx *gin.Context) {
 	var req createTodoRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		errDetails := util.SetErrorDetails(
-			"User manipulated invalid request data",
-			"User can see 400 Bad Request page",
-			"Request param tempered by client, no need to special issue handling",
-			"todo.go file, createTodo method",
-		)
-		log.Println(errDetails, err)
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid request data",
+			Effect:   "User can see 400 Bad Request page",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "todo.go file, createTodo method",
+		}, err)
 		ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
 		return
 	}
 
-	arg := db.CreateTodoParams{
-		Title:       sql.NullString{String: req.Title, Valid: true},
-		Description: sql.NullString{String: req.Description, Valid: true},
+	tagNames, err := tagNamesFromForm(req.Tags)
+	if err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid tag names",
+			Effect:   "User can see 400 Bad Request page",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "todo.go file, createTodo method",
+		}, err)
+		ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
+		return
+	}
+
+	user := currentUser(ctx)
+	arg := db.CreateTodoTxParams{
+		CreateTodoParams: db.CreateTodoParams{
+			UserID:      user.ID,
+			Title:       sql.NullString{String: req.Title, Valid: true},
+			Description: sql.NullString{String: req.Description, Valid: true},
+			Priority:    req.Priority,
+			DueAt:       dateFromForm(req.DueAt),
+		},
+		TagNames: tagNames,
 	}
-	result, dbErr := server.repo.CreateTodo(ctx, arg)
+	result, dbErr := server.repo.CreateTodoTx(ctx, arg)
 	if dbErr != nil {
-		errDetails := util.SetErrorDetails(
-			"Error occurred when inserting the data to DB",
-			"User can see 500 Internal Server Error page",
-			"This might be the database connection issue, please check the database status",
-			"todo.go file, createTodo method on CreateTodo query",
-		)
-		log.Println(errDetails, dbErr)
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when inserting the data to DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo.go file, createTodo method on CreateTodoTx transaction",
+		}, dbErr)
 		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
 		return
 	}
-	createdId, _ := result.LastInsertId()
 
-	ctx.Redirect(http.StatusFound, fmt.Sprintf("/show?id=%d", createdId))
+	ctx.Redirect(http.StatusFound, fmt.Sprintf("/show?id=%d", result.Todo.ID))
 }
 
 type getTodoRequest struct {
@@ -127,27 +205,31 @@ type getTodoRequest struct {
 func (server *Server) showTodo(ctx *gin.Context) {
 	var req getTodoRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		errDetails := util.SetErrorDetails(
-			"User manipulated invalid ID param",
-			"User can see 400 Bad Request page",
-			"Request param tempered by client, no need to special issue handling",
-			"todo.go file, showTodo method",
-		)
-		log.Println(errDetails, err)
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid ID param",
+			Effect:   "User can see 400 Bad Request page",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "todo.go file, showTodo method",
+		}, err)
 		ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
 		return
 	}
+	addLogAttrs(ctx, slog.Int64("todo_id", req.ID))
 
-	todo, dbErr := server.repo.GetTodo(ctx, req.ID)
+	user := currentUser(ctx)
+	arg := db.GetTodoParams{
+		ID:     req.ID,
+		UserID: user.ID,
+	}
+	todo, dbErr := server.repo.GetTodo(ctx, arg)
 	if dbErr != nil {
 		if dbErr == sql.ErrNoRows {
-			errDetails := util.SetErrorDetails(
-				"The selected data does not exist",
-				"User can see 500 Internal Server Error page",
-				"The data related request ID does not exist or this could be data inconsistency issue",
-				"todo.go file, showTodo method on GetDodo query",
-			)
-			log.Println(errDetails, dbErr)
+			logWarn(ctx, util.ErrorDetails{
+				Cause:    "The selected data does not exist",
+				Effect:   "User can see 500 Internal Server Error page",
+				Solution: "The data related request ID does not exist or this could be data inconsistency issue",
+				Position: "todo.go file, showTodo method on GetDodo query",
+			}, dbErr)
 			ctx.HTML(http.StatusNotFound, "500.html", gin.H{})
 			return
 		}
@@ -156,34 +238,55 @@ func (server *Server) showTodo(ctx *gin.Context) {
 		return
 	}
 
+	tags, dbErr := server.repo.ListTagsByTodo(ctx, db.ListTagsByTodoParams{
+		TodoID: todo.ID,
+		UserID: user.ID,
+	})
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the data from DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo.go file, showTodo method on ListTagsByTodo query",
+		}, dbErr)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
 	ctx.HTML(http.StatusOK, "show.html", gin.H{
-		"todo": todo,
+		"todo":  todo,
+		"tags":  tags,
+		"token": csrf.GetToken(ctx),
 	})
 }
 
 func (server *Server) editTodo(ctx *gin.Context) {
 	var req getTodoRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		errDetails := util.SetErrorDetails(
-			"User manipulated invalid ID param",
-			"User can see 400 Bad Request page",
-			"Request param tempered by client, no need to special issue handling",
-			"todo.go file, showTodo method",
-		)
-		log.Println(errDetails, err)
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid ID param",
+			Effect:   "User can see 400 Bad Request page",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "todo.go file, showTodo method",
+		}, err)
 		ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
 		return
 	}
+	addLogAttrs(ctx, slog.Int64("todo_id", req.ID))
 
-	todo, dbErr := server.repo.GetTodo(ctx, req.ID)
+	user := currentUser(ctx)
+	arg := db.GetTodoParams{
+		ID:     req.ID,
+		UserID: user.ID,
+	}
+	todo, dbErr := server.repo.GetTodo(ctx, arg)
 	if dbErr != nil {
-		errDetails := util.SetErrorDetails(
-			"Error occurred when fetching the data from DB",
-			"User can see 500 Internal Server Error page",
-			"This might be the database connection issue, please check the database status",
-			"todo.go file, listupTodo method on ListTodo query",
-		)
-		log.Println(errDetails, dbErr)
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the data from DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo.go file, listupTodo method on ListTodo query",
+		}, dbErr)
 		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
 		return
 	}
@@ -195,36 +298,43 @@ func (server *Server) editTodo(ctx *gin.Context) {
 }
 
 type updateTodoRequest struct {
-	ID          int64  `form:"id" binding:"required,numeric"`
-	Description string `form:"descriptionInput" binding:"required"`
+	ID          int64           `form:"id" binding:"required,numeric"`
+	Description string          `form:"descriptionInput" binding:"required"`
+	Status      db.TodoStatus   `form:"statusInput" binding:"required,oneof=open in_progress done"`
+	Priority    db.TodoPriority `form:"priorityInput" binding:"required,oneof=low medium high"`
+	DueAt       string          `form:"dueAtInput" binding:"omitempty,datetime=2006-01-02"`
 }
 
 func (server *Server) updateTodo(ctx *gin.Context) {
 	var req updateTodoRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		errDetails := util.SetErrorDetails(
-			"User manipulated invalid request data",
-			"User can see 400 Bad Request page",
-			"Request param tempered by client, no need to special issue handling",
-			"todo.go file, updateTodo method",
-		)
-		log.Println(errDetails, err)
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid request data",
+			Effect:   "User can see 400 Bad Request page",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "todo.go file, updateTodo method",
+		}, err)
 		ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
 		return
 	}
+	addLogAttrs(ctx, slog.Int64("todo_id", req.ID))
 
+	user := currentUser(ctx)
 	arg := db.UpdateTodoParams{
 		Description: sql.NullString{String: req.Description, Valid: true},
+		Status:      req.Status,
+		Priority:    req.Priority,
+		DueAt:       dateFromForm(req.DueAt),
 		ID:          req.ID,
+		UserID:      user.ID,
 	}
 	if dbErr := server.repo.UpdateTodo(ctx, arg); dbErr != nil {
-		errDetails := util.SetErrorDetails(
-			"Error occurred when updating the data on DB",
-			"User can see 500 Internal Server Error page",
-			"This might be the database connection issue, please check the database status",
-			"todo.go file, updateTodo method on UpdateTodo query",
-		)
-		log.Println(errDetails, dbErr)
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when updating the data on DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo.go file, updateTodo method on UpdateTodo query",
+		}, dbErr)
 		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
 		return
 	}
@@ -232,6 +342,90 @@ func (server *Server) updateTodo(ctx *gin.Context) {
 	ctx.Redirect(http.StatusFound, fmt.Sprintf("/show?id=%d", req.ID))
 }
 
+func (server *Server) toggleTodo(ctx *gin.Context) {
+	var req getTodoRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid ID param",
+			Effect:   "User can see 400 Bad Request page",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "todo.go file, toggleTodo method",
+		}, err)
+		ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
+		return
+	}
+	addLogAttrs(ctx, slog.Int64("todo_id", req.ID))
+
+	user := currentUser(ctx)
+	arg := db.ToggleTodoParams{
+		ID:     req.ID,
+		UserID: user.ID,
+	}
+	if dbErr := server.repo.ToggleTodo(ctx, arg); dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when updating the data on DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo.go file, toggleTodo method on ToggleTodo query",
+		}, dbErr)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, "/index")
+}
+
+// maxBulkDeleteSize caps the number of todos a single bulk delete may touch.
+const maxBulkDeleteSize = 100
+
+var errInvalidIDList = fmt.Errorf("ids must be a list of at most %d positive integers", maxBulkDeleteSize)
+
+// uniqueIDs drops repeated IDs, keeping the order.
+func uniqueIDs(ids []int64) []int64 {
+	unique := make([]int64, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// parseIDList parses the comma separated ids of the bulk delete form. Every
+// entry has to be a positive integer, the whole list is rejected otherwise.
+func parseIDList(value string) ([]int64, error) {
+	parts := strings.Split(value, ",")
+	ids := make([]int64, 0, len(parts))
+	for _, part := range parts {
+		id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil || id < 1 {
+			return nil, errInvalidIDList
+		}
+		ids = append(ids, id)
+	}
+
+	ids = uniqueIDs(ids)
+	if len(ids) > maxBulkDeleteSize {
+		return nil, errInvalidIDList
+	}
+	return ids, nil
+}
+
+// bulkDeleteMessage describes the result of a bulk delete for the flash.
+func bulkDeleteMessage(result db.DeleteTodoListTxResult) string {
+	message := fmt.Sprintf("Moved %d todos to the trash.", result.Deleted)
+	if len(result.NotFound) > 0 {
+		notFound := make([]string, len(result.NotFound))
+		for i, id := range result.NotFound {
+			notFound[i] = strconv.FormatInt(id, 10)
+		}
+		message += " Not found: " + strings.Join(notFound, ", ") + "."
+	}
+	return message
+}
+
 type deleteTodoRequest struct {
 	ID     int64  `form:"id" binding:"numeric"`
 	IDList string `form:"ids"`
@@ -240,43 +434,62 @@ type deleteTodoRequest struct {
 func (server *Server) deleteTodo(ctx *gin.Context) {
 	var req deleteTodoRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		errDetails := util.SetErrorDetails(
-			"User manipulated invalid request data",
-			"User can see 400 Bad Request page",
-			"Request param tempered by client, no need to special issue handling",
-			"todo.go file, deleteTodo method",
-		)
-		log.Println(errDetails, err)
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid request data",
+			Effect:   "User can see 400 Bad Request page",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "todo.go file, deleteTodo method",
+		}, err)
 		ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
 		return
 	}
 
+	user := currentUser(ctx)
 	if len(req.IDList) == 0 {
-		deleteId := req.ID
-		if dbErr := server.repo.DeleteTodo(ctx, deleteId); dbErr != nil {
-			errDetails := util.SetErrorDetails(
-				"Error occurred when deleting the data on DB",
-				"User can see 500 Internal Server Error page",
-				"This might be the database connection issue, please check the database status",
-				"todo.go file, deleteTodo method on DeleteTodo query",
-			)
-			log.Println(errDetails, dbErr)
+		addLogAttrs(ctx, slog.Int64("todo_id", req.ID))
+		arg := db.DeleteTodoParams{
+			ID:     req.ID,
+			UserID: user.ID,
+		}
+		if dbErr := server.repo.DeleteTodo(ctx, arg); dbErr != nil {
+			logError(ctx, util.ErrorDetails{
+				Cause:    "Error occurred when deleting the data on DB",
+				Effect:   "User can see 500 Internal Server Error page",
+				Solution: "This might be the database connection issue, please check the database status",
+				Position: "todo.go file, deleteTodo method on DeleteTodo query",
+			}, dbErr)
 			ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
 			return
 		}
 	} else {
-		deleteIds := req.IDList
-		if dbErr := server.repo.DeleteTodoList(ctx, deleteIds); dbErr != nil {
-			errDetails := util.SetErrorDetails(
-				"Error occurred when deleting the data on DB",
-				"User can see 500 Internal Server Error page",
-				"This might be the database connection issue, please check the database status",
-				"todo.go file, deleteTodo method on DeleteTodoList query",
-			)
-			log.Println(errDetails, dbErr)
+		ids, err := parseIDList(req.IDList)
+		if err != nil {
+			logWarn(ctx, util.ErrorDetails{
+				Cause:    "User manipulated invalid ids param",
+				Effect:   "User can see 400 Bad Request page",
+				Solution: "Request param tempered by client, no need to special issue handling",
+				Position: "todo.go file, deleteTodo method",
+			}, err)
+			ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
+			return
+		}
+
+		arg := db.DeleteTodoListParams{
+			UserID: user.ID,
+			IDs:    ids,
+		}
+		result, dbErr := server.repo.DeleteTodoListTx(ctx, arg)
+		if dbErr != nil {
+			logError(ctx, util.ErrorDetails{
+				Cause:    "Error occurred when deleting the data on DB",
+				Effect:   "User can see 500 Internal Server Error page",
+				Solution: "This might be the database connection issue, please check the database status",
+				Position: "todo.go file, deleteTodo method on DeleteTodoListTx transaction",
+			}, dbErr)
 			ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
 			return
 		}
+		addFlash(ctx, bulkDeleteMessage(result))
 	}
 
 	ctx.Redirect(http.StatusFound, "/index")
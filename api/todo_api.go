@@ -0,0 +1,566 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	db "first-app/todo_go/db/sqlc"
+	"first-app/todo_go/util"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiError is the structured error body returned by every JSON endpoint.
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func errorResponse(code, message string) gin.H {
+	return gin.H{"error": apiError{Code: code, Message: message}}
+}
+
+type listTodoJSONRequest struct {
+	listTodoFilter
+	Page   int    `form:"page,default=1" binding:"min=1"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1"`
+	Cursor string `form:"cursor"`
+}
+
+type listTodoJSONResponse struct {
+	Todos []db.Todo `json:"todos"`
+	Total int64     `json:"total"`
+	Page  int       `json:"page"`
+	Limit int       `json:"limit"`
+	Query string    `json:"q,omitempty"`
+}
+
+// listTodoCursorJSONResponse is a page of the list in cursor mode. The list
+// is not counted, so there is no total, and the cursors are left out on the
+// first and the last page.
+type listTodoCursorJSONResponse struct {
+	Todos      []db.Todo `json:"todos"`
+	Limit      int       `json:"limit"`
+	Query      string    `json:"q,omitempty"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	PrevCursor string    `json:"prev_cursor,omitempty"`
+}
+
+// listTodoJSON pages through the list by page number, or by cursor when the
+// cursor param is given. An empty cursor starts from the first page. limit
+// defaults to the page size of the server and may not exceed its max.
+func (server *Server) listTodoJSON(ctx *gin.Context) {
+	var req listTodoJSONRequest
+	err := ctx.ShouldBindQuery(&req)
+	if err == nil && req.Limit > server.maxPageSize {
+		err = fmt.Errorf("limit must be at most %d", server.maxPageSize)
+	}
+	if req.Limit == 0 {
+		req.Limit = server.pageSize
+	}
+	// The offset of the page must fit the int32 of the query.
+	if err == nil && int64(req.Page-1) > math.MaxInt32/int64(req.Limit) {
+		err = errPageOutOfRange
+	}
+	if err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid page, limit, sort or filter param",
+			Effect:   "User can see 400 Bad Request response",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "todo_api.go file, listTodoJSON method",
+		}, err)
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid_request", err.Error()))
+		return
+	}
+
+	req.Query = strings.TrimSpace(req.Query)
+	if _, ok := ctx.GetQuery("cursor"); ok {
+		server.listTodoCursorJSON(ctx, req)
+		return
+	}
+
+	user := currentUser(ctx)
+	total, dbErr := server.repo.CountTodo(ctx, req.todoFilter(user.ID))
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the data from DB",
+			Effect:   "User can see 500 Internal Server Error response",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo_api.go file, listTodoJSON method on CountTodo query",
+		}, dbErr)
+		ctx.JSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to count todos"))
+		return
+	}
+
+	todoList, dbErr := server.repo.ListTodo(ctx, req.listTodoParams(user.ID, int32((req.Page-1)*req.Limit), int32(req.Limit)))
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the data from DB",
+			Effect:   "User can see 500 Internal Server Error response",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo_api.go file, listTodoJSON method on ListTodo query",
+		}, dbErr)
+		ctx.JSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to list todos"))
+		return
+	}
+	if todoList == nil {
+		todoList = []db.Todo{}
+	}
+
+	ctx.JSON(http.StatusOK, listTodoJSONResponse{
+		Todos: todoList,
+		Total: total,
+		Page:  req.Page,
+		Limit: req.Limit,
+		Query: req.Query,
+	})
+}
+
+var (
+	errPageOutOfRange = errors.New("page is out of range")
+	errCursorWithPage = errors.New("cursor cannot be combined with page")
+	errCursorSort     = errors.New("cursor does not match the sort and order")
+)
+
+func (server *Server) listTodoCursorJSON(ctx *gin.Context, req listTodoJSONRequest) {
+	var cursor *db.TodoCursor
+	err := func() error {
+		if _, ok := ctx.GetQuery("page"); ok {
+			return errCursorWithPage
+		}
+		if len(req.Cursor) == 0 {
+			return nil
+		}
+		decoded, err := db.DecodeTodoCursor(req.Cursor)
+		if err != nil {
+			return err
+		}
+		if !decoded.Matches(db.TodoSortField(req.Sort), db.SortOrder(req.Order)) {
+			return errCursorSort
+		}
+		cursor = &decoded
+		return nil
+	}()
+	if err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User sent an invalid cursor or combined it with a page",
+			Effect:   "User can see 400 Bad Request response",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "todo_api.go file, listTodoCursorJSON method",
+		}, err)
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid_request", err.Error()))
+		return
+	}
+
+	user := currentUser(ctx)
+	arg := req.listTodoParams(user.ID, 0, int32(req.Limit))
+	arg.Cursor = cursor
+	page, dbErr := db.ListTodoPage(ctx, server.repo, arg)
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the data from DB",
+			Effect:   "User can see 500 Internal Server Error response",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo_api.go file, listTodoCursorJSON method on ListTodo query",
+		}, dbErr)
+		ctx.JSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to list todos"))
+		return
+	}
+
+	res := listTodoCursorJSONResponse{
+		Todos: page.Todos,
+		Limit: req.Limit,
+		Query: req.Query,
+	}
+	if res.Todos == nil {
+		res.Todos = []db.Todo{}
+	}
+	if page.Next != nil {
+		res.NextCursor = page.Next.Encode()
+	}
+	if page.Prev != nil {
+		res.PrevCursor = page.Prev.Encode()
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+type todoURIRequest struct {
+	ID int64 `uri:"id" binding:"min=1"`
+}
+
+// fetchTodoJSON loads the todo addressed by the :id path param and writes the
+// matching error response when it cannot. The bool result reports success.
+func (server *Server) fetchTodoJSON(ctx *gin.Context, position string) (db.Todo, bool) {
+	var req todoURIRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid ID param",
+			Effect:   "User can see 400 Bad Request response",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: position,
+		}, err)
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid_request", err.Error()))
+		return db.Todo{}, false
+	}
+	addLogAttrs(ctx, slog.Int64("todo_id", req.ID))
+
+	user := currentUser(ctx)
+	arg := db.GetTodoParams{
+		ID:     req.ID,
+		UserID: user.ID,
+	}
+	todo, dbErr := server.repo.GetTodo(ctx, arg)
+	if dbErr != nil {
+		if dbErr == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse("not_found", "todo not found"))
+			return db.Todo{}, false
+		}
+
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the data from DB",
+			Effect:   "User can see 500 Internal Server Error response",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: position + " on GetTodo query",
+		}, dbErr)
+		ctx.JSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to fetch todo"))
+		return db.Todo{}, false
+	}
+
+	return todo, true
+}
+
+func (server *Server) getTodoJSON(ctx *gin.Context) {
+	todo, ok := server.fetchTodoJSON(ctx, "todo_api.go file, getTodoJSON method")
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, todo)
+}
+
+type createTodoJSONRequest struct {
+	Title       string          `json:"title" binding:"required"`
+	Description string          `json:"description" binding:"required"`
+	Priority    db.TodoPriority `json:"priority" binding:"omitempty,oneof=low medium high"`
+	DueAt       *time.Time      `json:"due_at"`
+	Tags        []string        `json:"tags" binding:"max=20"`
+}
+
+// createTodoJSONResponse is the created todo together with its tags.
+type createTodoJSONResponse struct {
+	db.Todo
+	Tags []db.Tag `json:"tags"`
+}
+
+func nullTimeFromJSON(value *time.Time) sql.NullTime {
+	if value == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *value, Valid: true}
+}
+
+func (server *Server) createTodoJSON(ctx *gin.Context) {
+	var req createTodoJSONRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid request data",
+			Effect:   "User can see 400 Bad Request response",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "todo_api.go file, createTodoJSON method",
+		}, err)
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid_request", err.Error()))
+		return
+	}
+
+	tagNames, err := normalizeTagNames(req.Tags)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid_request", err.Error()))
+		return
+	}
+
+	user := currentUser(ctx)
+	arg := db.CreateTodoTxParams{
+		CreateTodoParams: db.CreateTodoParams{
+			UserID:      user.ID,
+			Title:       sql.NullString{String: req.Title, Valid: true},
+			Description: sql.NullString{String: req.Description, Valid: true},
+			Priority:    req.Priority,
+			DueAt:       nullTimeFromJSON(req.DueAt),
+		},
+		TagNames: tagNames,
+	}
+	if len(arg.Priority) == 0 {
+		arg.Priority = db.TodoPriorityMedium
+	}
+	result, dbErr := server.repo.CreateTodoTx(ctx, arg)
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when inserting the data to DB",
+			Effect:   "User can see 500 Internal Server Error response",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo_api.go file, createTodoJSON method on CreateTodoTx transaction",
+		}, dbErr)
+		ctx.JSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to create todo"))
+		return
+	}
+	if result.Tags == nil {
+		result.Tags = []db.Tag{}
+	}
+
+	ctx.JSON(http.StatusCreated, createTodoJSONResponse{
+		Todo: result.Todo,
+		Tags: result.Tags,
+	})
+}
+
+// updateTodoJSONRequest leaves status, priority and due_at untouched when
+// they are omitted from the body.
+type updateTodoJSONRequest struct {
+	Description string          `json:"description" binding:"required"`
+	Status      db.TodoStatus   `json:"status" binding:"omitempty,oneof=open in_progress done"`
+	Priority    db.TodoPriority `json:"priority" binding:"omitempty,oneof=low medium high"`
+	DueAt       *time.Time      `json:"due_at"`
+}
+
+func (server *Server) updateTodoJSON(ctx *gin.Context) {
+	todo, ok := server.fetchTodoJSON(ctx, "todo_api.go file, updateTodoJSON method")
+	if !ok {
+		return
+	}
+
+	var req updateTodoJSONRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid request data",
+			Effect:   "User can see 400 Bad Request response",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "todo_api.go file, updateTodoJSON method",
+		}, err)
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid_request", err.Error()))
+		return
+	}
+
+	user := currentUser(ctx)
+	arg := db.UpdateTodoParams{
+		Description: sql.NullString{String: req.Description, Valid: true},
+		Status:      todo.Status,
+		Priority:    todo.Priority,
+		DueAt:       todo.DueAt,
+		ID:          todo.ID,
+		UserID:      user.ID,
+	}
+	if len(req.Status) > 0 {
+		arg.Status = req.Status
+	}
+	if len(req.Priority) > 0 {
+		arg.Priority = req.Priority
+	}
+	if req.DueAt != nil {
+		arg.DueAt = nullTimeFromJSON(req.DueAt)
+	}
+	if dbErr := server.repo.UpdateTodo(ctx, arg); dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when updating the data on DB",
+			Effect:   "User can see 500 Internal Server Error response",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo_api.go file, updateTodoJSON method on UpdateTodo query",
+		}, dbErr)
+		ctx.JSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to update todo"))
+		return
+	}
+
+	todo, ok = server.fetchTodoJSON(ctx, "todo_api.go file, updateTodoJSON method")
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, todo)
+}
+
+// optionalTime tells a field set to null, which clears it, from one left out
+// of the body.
+type optionalTime struct {
+	Set  bool
+	Time *time.Time
+}
+
+func (value *optionalTime) UnmarshalJSON(data []byte) error {
+	value.Set = true
+	value.Time = nil
+	if string(data) == "null" {
+		return nil
+	}
+	return json.Unmarshal(data, &value.Time)
+}
+
+// patchTodoJSONRequest only holds the fields given in the body. A due_at of
+// null clears the due date.
+type patchTodoJSONRequest struct {
+	Description *string          `json:"description"`
+	Status      *db.TodoStatus   `json:"status" binding:"omitempty,oneof=open in_progress done"`
+	Priority    *db.TodoPriority `json:"priority" binding:"omitempty,oneof=low medium high"`
+	DueAt       optionalTime     `json:"due_at"`
+}
+
+// patchTodoJSON updates the fields given in the body and keeps the stored
+// values of the others.
+func (server *Server) patchTodoJSON(ctx *gin.Context) {
+	todo, ok := server.fetchTodoJSON(ctx, "todo_api.go file, patchTodoJSON method")
+	if !ok {
+		return
+	}
+
+	var req patchTodoJSONRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid request data",
+			Effect:   "User can see 400 Bad Request response",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "todo_api.go file, patchTodoJSON method",
+		}, err)
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid_request", err.Error()))
+		return
+	}
+
+	user := currentUser(ctx)
+	arg := db.UpdateTodoParams{
+		Description: todo.Description,
+		Status:      todo.Status,
+		Priority:    todo.Priority,
+		DueAt:       todo.DueAt,
+		ID:          todo.ID,
+		UserID:      user.ID,
+	}
+	if req.Description != nil {
+		arg.Description = sql.NullString{String: *req.Description, Valid: true}
+	}
+	if req.Status != nil {
+		arg.Status = *req.Status
+	}
+	if req.Priority != nil {
+		arg.Priority = *req.Priority
+	}
+	if req.DueAt.Set {
+		arg.DueAt = nullTimeFromJSON(req.DueAt.Time)
+	}
+	if dbErr := server.repo.UpdateTodo(ctx, arg); dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when updating the data on DB",
+			Effect:   "User can see 500 Internal Server Error response",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo_api.go file, patchTodoJSON method on UpdateTodo query",
+		}, dbErr)
+		ctx.JSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to update todo"))
+		return
+	}
+
+	todo, ok = server.fetchTodoJSON(ctx, "todo_api.go file, patchTodoJSON method")
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, todo)
+}
+
+func (server *Server) toggleTodoJSON(ctx *gin.Context) {
+	todo, ok := server.fetchTodoJSON(ctx, "todo_api.go file, toggleTodoJSON method")
+	if !ok {
+		return
+	}
+
+	user := currentUser(ctx)
+	arg := db.ToggleTodoParams{
+		ID:     todo.ID,
+		UserID: user.ID,
+	}
+	if dbErr := server.repo.ToggleTodo(ctx, arg); dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when updating the data on DB",
+			Effect:   "User can see 500 Internal Server Error response",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo_api.go file, toggleTodoJSON method on ToggleTodo query",
+		}, dbErr)
+		ctx.JSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to toggle todo"))
+		return
+	}
+
+	todo, ok = server.fetchTodoJSON(ctx, "todo_api.go file, toggleTodoJSON method")
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, todo)
+}
+
+func (server *Server) deleteTodoJSON(ctx *gin.Context) {
+	todo, ok := server.fetchTodoJSON(ctx, "todo_api.go file, deleteTodoJSON method")
+	if !ok {
+		return
+	}
+
+	user := currentUser(ctx)
+	arg := db.DeleteTodoParams{
+		ID:     todo.ID,
+		UserID: user.ID,
+	}
+	if dbErr := server.repo.DeleteTodo(ctx, arg); dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when deleting the data on DB",
+			Effect:   "User can see 500 Internal Server Error response",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo_api.go file, deleteTodoJSON method on DeleteTodo query",
+		}, dbErr)
+		ctx.JSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to delete todo"))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type deleteTodoListJSONRequest struct {
+	IDs []int64 `json:"ids" binding:"required,min=1,dive,min=1"`
+}
+
+func (server *Server) deleteTodoListJSON(ctx *gin.Context) {
+	var req deleteTodoListJSONRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid request data",
+			Effect:   "User can see 400 Bad Request response",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "todo_api.go file, deleteTodoListJSON method",
+		}, err)
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid_request", err.Error()))
+		return
+	}
+	ids := uniqueIDs(req.IDs)
+	if len(ids) > maxBulkDeleteSize {
+		ctx.JSON(http.StatusBadRequest, errorResponse("invalid_request", errInvalidIDList.Error()))
+		return
+	}
+
+	user := currentUser(ctx)
+	arg := db.DeleteTodoListParams{
+		UserID: user.ID,
+		IDs:    ids,
+	}
+	result, dbErr := server.repo.DeleteTodoListTx(ctx, arg)
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when deleting the data on DB",
+			Effect:   "User can see 500 Internal Server Error response",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "todo_api.go file, deleteTodoListJSON method on DeleteTodoListTx transaction",
+		}, dbErr)
+		ctx.JSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to delete todos"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,1238 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	mockdb "first-app/todo_go/db/mock"
+	db "first-app/todo_go/db/sqlc"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListTodoJSON(t *testing.T) {
+	user := randomUser(t)
+	todoList := []db.Todo{randomTodo(user), randomTodo(user)}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "page=2&limit=2",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Eq(db.TodoFilter{UserID: user.ID})).
+					Times(1).
+					Return(int64(4), nil)
+
+				arg := db.ListTodoParams{
+					TodoFilter: db.TodoFilter{UserID: user.ID},
+					Offset:     2,
+					Limit:      2,
+				}
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(todoList, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got listTodoJSONResponse
+				requireBodyDecodes(t, recorder.Body, &got)
+				require.Equal(t, todoList, got.Todos)
+				require.Equal(t, int64(4), got.Total)
+				require.Equal(t, 2, got.Page)
+				require.Equal(t, 2, got.Limit)
+			},
+		},
+		{
+			name:  "OK with search",
+			query: "q=+dog+walk+&limit=2",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Eq(db.TodoFilter{UserID: user.ID, Query: "dog walk"})).
+					Times(1).
+					Return(int64(2), nil)
+
+				arg := db.ListTodoParams{
+					TodoFilter: db.TodoFilter{UserID: user.ID, Query: "dog walk"},
+					Offset:     0,
+					Limit:      2,
+				}
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(todoList, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got listTodoJSONResponse
+				requireBodyDecodes(t, recorder.Body, &got)
+				require.Equal(t, todoList, got.Todos)
+				require.Equal(t, int64(2), got.Total)
+				require.Equal(t, "dog walk", got.Query)
+			},
+		},
+		{
+			name:  "Bad Request",
+			query: "page=0",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name:  "Limit Over Max",
+			query: "limit=101",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name:  "Page Out Of Range",
+			query: "page=50000000&limit=100",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name:  "Largest Offset",
+			query: "page=2147483648&limit=1",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Eq(db.TodoFilter{UserID: user.ID})).
+					Times(1).
+					Return(int64(len(todoList)), nil)
+
+				arg := db.ListTodoParams{
+					TodoFilter: db.TodoFilter{UserID: user.ID},
+					Offset:     math.MaxInt32,
+					Limit:      1,
+				}
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.Todo{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "Internal Error",
+			query: "",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Eq(db.TodoFilter{UserID: user.ID})).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder.Body, "internal_error")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			targetUrl := fmt.Sprintf("/api/v1/todos?%s", tc.query)
+			request, err := http.NewRequest(http.MethodGet, targetUrl, nil)
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListTodoCursorJSON(t *testing.T) {
+	user := randomUser(t)
+	todoList := []db.Todo{randomTodo(user), randomTodo(user), randomTodo(user)}
+	next := db.NewTodoCursor(todoList[1], db.TodoSortID, db.SortAsc, false)
+	titleCursor := db.NewTodoCursor(todoList[0], db.TodoSortTitle, db.SortDesc, false)
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "First Page",
+			query: "cursor=&limit=2",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				arg := db.ListTodoParams{
+					TodoFilter: db.TodoFilter{UserID: user.ID},
+					Limit:      3,
+				}
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(todoList, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got listTodoCursorJSONResponse
+				requireBodyDecodes(t, recorder.Body, &got)
+				require.Equal(t, todoList[:2], got.Todos)
+				require.Equal(t, 2, got.Limit)
+				require.Equal(t, next.Encode(), got.NextCursor)
+				require.Empty(t, got.PrevCursor)
+			},
+		},
+		{
+			name:  "Next Page",
+			query: "limit=2&cursor=" + next.Encode(),
+			buildStubs: func(repo *mockdb.MockRepo) {
+				arg := db.ListTodoParams{
+					TodoFilter: db.TodoFilter{UserID: user.ID},
+					Limit:      3,
+					Cursor:     &next,
+				}
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(todoList[2:], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got listTodoCursorJSONResponse
+				requireBodyDecodes(t, recorder.Body, &got)
+				require.Equal(t, todoList[2:], got.Todos)
+				require.Empty(t, got.NextCursor)
+				prev := db.NewTodoCursor(todoList[2], db.TodoSortID, db.SortAsc, true)
+				require.Equal(t, prev.Encode(), got.PrevCursor)
+			},
+		},
+		{
+			name:  "Sorted Page",
+			query: "sort=title&order=desc&cursor=" + titleCursor.Encode(),
+			buildStubs: func(repo *mockdb.MockRepo) {
+				arg := db.ListTodoParams{
+					TodoFilter: db.TodoFilter{UserID: user.ID},
+					SortBy:     db.TodoSortTitle,
+					SortOrder:  db.SortDesc,
+					Limit:      6,
+					Cursor:     &titleCursor,
+				}
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.Todo{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got listTodoCursorJSONResponse
+				requireBodyDecodes(t, recorder.Body, &got)
+				require.NotNil(t, got.Todos)
+				require.Empty(t, got.Todos)
+				require.Empty(t, got.NextCursor)
+				require.Empty(t, got.PrevCursor)
+			},
+		},
+		{
+			name:  "Invalid Cursor",
+			query: "cursor=not-a-cursor",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name:  "Cursor Of Another Sort",
+			query: "sort=title&cursor=" + next.Encode(),
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name:  "Cursor With Page",
+			query: "page=2&cursor=",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name:  "Internal Error",
+			query: "cursor=",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder.Body, "internal_error")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			targetUrl := fmt.Sprintf("/api/v1/todos?%s", tc.query)
+			request, err := http.NewRequest(http.MethodGet, targetUrl, nil)
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetTodoJSON(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+
+	testCases := []struct {
+		name          string
+		todoID        int64
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			todoID: todo.ID,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(todo, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchTodo(t, recorder.Body, todo)
+			},
+		},
+		{
+			name:   "Not Found",
+			todoID: todo.ID,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(db.Todo{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder.Body, "not_found")
+			},
+		},
+		{
+			name:   "Internal Error",
+			todoID: todo.ID,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(db.Todo{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder.Body, "internal_error")
+			},
+		},
+		{
+			name:   "Invalid ID",
+			todoID: 0,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			targetUrl := fmt.Sprintf("/api/v1/todos/%d", tc.todoID)
+			request, err := http.NewRequest(http.MethodGet, targetUrl, nil)
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateTodoJSON(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Created",
+			body: map[string]interface{}{
+				"title":       todo.Title.String,
+				"description": todo.Description.String,
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				arg := db.CreateTodoTxParams{
+					CreateTodoParams: db.CreateTodoParams{
+						UserID:      user.ID,
+						Title:       todo.Title,
+						Description: todo.Description,
+						Priority:    db.TodoPriorityMedium,
+					},
+					TagNames: []string{},
+				}
+
+				repo.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CreateTodoTxResult{Todo: todo}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"tags":[]`)
+				requireBodyMatchTodo(t, recorder.Body, todo)
+			},
+		},
+		{
+			name: "Created with tags",
+			body: map[string]interface{}{
+				"title":       todo.Title.String,
+				"description": todo.Description.String,
+				"tags":        []string{"work", " work "},
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				arg := db.CreateTodoTxParams{
+					CreateTodoParams: db.CreateTodoParams{
+						UserID:      user.ID,
+						Title:       todo.Title,
+						Description: todo.Description,
+						Priority:    db.TodoPriorityMedium,
+					},
+					TagNames: []string{"work"},
+				}
+				tags := []db.Tag{{ID: 1, UserID: user.ID, Name: "work"}}
+
+				repo.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CreateTodoTxResult{Todo: todo, Tags: tags}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got createTodoJSONResponse
+				requireBodyDecodes(t, recorder.Body, &got)
+				require.Len(t, got.Tags, 1)
+				require.Equal(t, "work", got.Tags[0].Name)
+			},
+		},
+		{
+			name: "Blank Tag",
+			body: map[string]interface{}{
+				"title":       todo.Title.String,
+				"description": todo.Description.String,
+				"tags":        []string{" "},
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name: "Bad Request",
+			body: map[string]interface{}{
+				"title": todo.Title.String,
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name: "Internal Error",
+			body: map[string]interface{}{
+				"title":       todo.Title.String,
+				"description": todo.Description.String,
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateTodoTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder.Body, "internal_error")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			targetUrl := "/api/v1/todos"
+			request, err := http.NewRequest(http.MethodPost, targetUrl, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateTodoJSON(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+	updated := todo
+	updated.Description = sql.NullString{String: "updated description", Valid: true}
+	dueTodo := todo
+	dueTodo.DueAt = sql.NullTime{Time: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC), Valid: true}
+
+	testCases := []struct {
+		name          string
+		method        string
+		body          map[string]interface{}
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK with PUT",
+			method: http.MethodPut,
+			body: map[string]interface{}{
+				"description": updated.Description.String,
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				arg := db.UpdateTodoParams{
+					Description: updated.Description,
+					Status:      todo.Status,
+					Priority:    todo.Priority,
+					DueAt:       todo.DueAt,
+					ID:          todo.ID,
+					UserID:      user.ID,
+				}
+
+				gomock.InOrder(
+					repo.EXPECT().
+						GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+						Times(1).
+						Return(todo, nil),
+					repo.EXPECT().
+						UpdateTodo(gomock.Any(), gomock.Eq(arg)).
+						Times(1).
+						Return(nil),
+					repo.EXPECT().
+						GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+						Times(1).
+						Return(updated, nil),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchTodo(t, recorder.Body, updated)
+			},
+		},
+		{
+			name:   "OK with PATCH",
+			method: http.MethodPatch,
+			body: map[string]interface{}{
+				"description": updated.Description.String,
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(2).
+					Return(updated, nil)
+
+				repo.EXPECT().
+					UpdateTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "OK with status",
+			method: http.MethodPatch,
+			body: map[string]interface{}{
+				"description": updated.Description.String,
+				"status":      db.TodoStatusInProgress,
+				"priority":    db.TodoPriorityHigh,
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				arg := db.UpdateTodoParams{
+					Description: updated.Description,
+					Status:      db.TodoStatusInProgress,
+					Priority:    db.TodoPriorityHigh,
+					DueAt:       todo.DueAt,
+					ID:          todo.ID,
+					UserID:      user.ID,
+				}
+
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(2).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					UpdateTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Partial PATCH",
+			method: http.MethodPatch,
+			body: map[string]interface{}{
+				"status": db.TodoStatusDone,
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				arg := db.UpdateTodoParams{
+					Description: dueTodo.Description,
+					Status:      db.TodoStatusDone,
+					Priority:    dueTodo.Priority,
+					DueAt:       dueTodo.DueAt,
+					ID:          dueTodo.ID,
+					UserID:      user.ID,
+				}
+
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(2).
+					Return(dueTodo, nil)
+
+				repo.EXPECT().
+					UpdateTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "PATCH clears due_at",
+			method: http.MethodPatch,
+			body: map[string]interface{}{
+				"due_at": nil,
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				arg := db.UpdateTodoParams{
+					Description: dueTodo.Description,
+					Status:      dueTodo.Status,
+					Priority:    dueTodo.Priority,
+					ID:          dueTodo.ID,
+					UserID:      user.ID,
+				}
+
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(2).
+					Return(dueTodo, nil)
+
+				repo.EXPECT().
+					UpdateTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "PATCH Empty Status",
+			method: http.MethodPatch,
+			body: map[string]interface{}{
+				"status": "",
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					UpdateTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name:   "Invalid Status",
+			method: http.MethodPatch,
+			body: map[string]interface{}{
+				"description": updated.Description.String,
+				"status":      "archived",
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					UpdateTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name:   "Not Found",
+			method: http.MethodPut,
+			body: map[string]interface{}{
+				"description": updated.Description.String,
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(db.Todo{}, sql.ErrNoRows)
+
+				repo.EXPECT().
+					UpdateTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder.Body, "not_found")
+			},
+		},
+		{
+			name:   "Bad Request",
+			method: http.MethodPut,
+			body:   map[string]interface{}{},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					UpdateTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name:   "Internal Error",
+			method: http.MethodPut,
+			body: map[string]interface{}{
+				"description": updated.Description.String,
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					UpdateTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder.Body, "internal_error")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			targetUrl := fmt.Sprintf("/api/v1/todos/%d", todo.ID)
+			request, err := http.NewRequest(tc.method, targetUrl, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestToggleTodoJSON(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+	toggled := todo
+	toggled.Status = db.TodoStatusDone
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				gomock.InOrder(
+					repo.EXPECT().
+						GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+						Times(1).
+						Return(todo, nil),
+					repo.EXPECT().
+						ToggleTodo(gomock.Any(), gomock.Eq(db.ToggleTodoParams{ID: todo.ID, UserID: user.ID})).
+						Times(1).
+						Return(nil),
+					repo.EXPECT().
+						GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+						Times(1).
+						Return(toggled, nil),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchTodo(t, recorder.Body, toggled)
+			},
+		},
+		{
+			name: "Not Found",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Todo{}, sql.ErrNoRows)
+
+				repo.EXPECT().
+					ToggleTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder.Body, "not_found")
+			},
+		},
+		{
+			name: "Internal Error",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					ToggleTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder.Body, "internal_error")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			targetUrl := fmt.Sprintf("/api/v1/todos/%d/toggle", todo.ID)
+			request, err := http.NewRequest(http.MethodPost, targetUrl, nil)
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteTodoJSON(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "No Content",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					DeleteTodo(gomock.Any(), gomock.Eq(db.DeleteTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "Not Found",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(db.Todo{}, sql.ErrNoRows)
+
+				repo.EXPECT().
+					DeleteTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder.Body, "not_found")
+			},
+		},
+		{
+			name: "Internal Error",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					DeleteTodo(gomock.Any(), gomock.Eq(db.DeleteTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder.Body, "internal_error")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			targetUrl := fmt.Sprintf("/api/v1/todos/%d", todo.ID)
+			request, err := http.NewRequest(http.MethodDelete, targetUrl, nil)
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteTodoListJSON(t *testing.T) {
+	user := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]interface{}{"ids": []int64{4, 8, 4, 15}},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				arg := db.DeleteTodoListParams{UserID: user.ID, IDs: []int64{4, 8, 15}}
+				repo.EXPECT().
+					DeleteTodoListTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.DeleteTodoListTxResult{Deleted: 2, NotFound: []int64{15}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, `{"deleted": 2, "not_found": [15]}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "Empty List",
+			body: map[string]interface{}{"ids": []int64{}},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					DeleteTodoListTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name: "Invalid ID",
+			body: map[string]interface{}{"ids": []interface{}{1, "abc"}},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					DeleteTodoListTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name: "Too Many IDs",
+			body: map[string]interface{}{"ids": func() []int64 {
+				ids := make([]int64, maxBulkDeleteSize+1)
+				for i := range ids {
+					ids[i] = int64(i + 1)
+				}
+				return ids
+			}()},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					DeleteTodoListTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder.Body, "invalid_request")
+			},
+		},
+		{
+			name: "Internal Error",
+			body: map[string]interface{}{"ids": []int64{4}},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					DeleteTodoListTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DeleteTodoListTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder.Body, "internal_error")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			targetUrl := "/api/v1/todos/bulk-delete"
+			request, err := http.NewRequest(http.MethodPost, targetUrl, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func requireBodyDecodes(t *testing.T, body io.Reader, v interface{}) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	err = json.Unmarshal(data, v)
+	require.NoError(t, err)
+}
+
+func requireBodyMatchTodo(t *testing.T, body io.Reader, todo db.Todo) {
+	var got db.Todo
+	requireBodyDecodes(t, body, &got)
+	require.Equal(t, todo, got)
+}
+
+func requireErrorCode(t *testing.T, body io.Reader, code string) {
+	var got struct {
+		Error apiError `json:"error"`
+	}
+	requireBodyDecodes(t, body, &got)
+	require.Equal(t, code, got.Error.Code)
+	require.NotEmpty(t, got.Error.Message)
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"database/sql"
+	db "first-app/todo_go/db/sqlc"
+	"net/url"
+	"strings"
+)
+
+// listTodoFilter holds the search, sort and filter params shared by the list
+// page and the JSON list endpoint. The date filters are inclusive days.
+type listTodoFilter struct {
+	Query       string `form:"q"`
+	Tag         string `form:"tag" binding:"max=64"`
+	Sort        string `form:"sort" binding:"omitempty,oneof=id create_date update_date title"`
+	Order       string `form:"order" binding:"omitempty,oneof=asc desc"`
+	CreatedFrom string `form:"created_from" binding:"omitempty,datetime=2006-01-02"`
+	CreatedTo   string `form:"created_to" binding:"omitempty,datetime=2006-01-02"`
+	DueFrom     string `form:"due_from" binding:"omitempty,datetime=2006-01-02"`
+	DueTo       string `form:"due_to" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// sortFields are the columns the list page offers as sortable headers.
+var sortFields = []db.TodoSortField{
+	db.TodoSortID,
+	db.TodoSortTitle,
+	db.TodoSortCreateDate,
+	db.TodoSortUpdateDate,
+}
+
+// endOfDay turns an inclusive day into the exclusive bound TodoFilter expects.
+func endOfDay(value string) sql.NullTime {
+	date := dateFromForm(value)
+	if date.Valid {
+		date.Time = date.Time.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+func (f listTodoFilter) todoFilter(userID int64) db.TodoFilter {
+	return db.TodoFilter{
+		UserID:      userID,
+		Query:       strings.TrimSpace(f.Query),
+		Tag:         f.Tag,
+		CreatedFrom: dateFromForm(f.CreatedFrom),
+		CreatedTo:   endOfDay(f.CreatedTo),
+		DueFrom:     dateFromForm(f.DueFrom),
+		DueTo:       endOfDay(f.DueTo),
+	}
+}
+
+func (f listTodoFilter) listTodoParams(userID int64, offset, limit int32) db.ListTodoParams {
+	return db.ListTodoParams{
+		TodoFilter: f.todoFilter(userID),
+		SortBy:     db.TodoSortField(f.Sort),
+		SortOrder:  db.SortOrder(f.Order),
+		Offset:     offset,
+		Limit:      limit,
+	}
+}
+
+// values encodes the non-empty params back into a query string.
+func (f listTodoFilter) values() url.Values {
+	values := url.Values{}
+	params := []struct {
+		key   string
+		value string
+	}{
+		{"q", strings.TrimSpace(f.Query)},
+		{"tag", f.Tag},
+		{"sort", f.Sort},
+		{"order", f.Order},
+		{"created_from", f.CreatedFrom},
+		{"created_to", f.CreatedTo},
+		{"due_from", f.DueFrom},
+		{"due_to", f.DueTo},
+	}
+	for _, param := range params {
+		if len(param.value) > 0 {
+			values.Set(param.key, param.value)
+		}
+	}
+	return values
+}
+
+// pageLink returns the query string the pagination links append after the
+// page number, so that moving between pages keeps the search, sort and filters.
+func (f listTodoFilter) pageLink() string {
+	values := f.values()
+	if len(values) == 0 {
+		return ""
+	}
+	return "&" + values.Encode()
+}
+
+// sortLinks returns the link of every sortable header. Clicking the header of
+// the current sort column flips the order, any other header sorts ascending.
+func (f listTodoFilter) sortLinks() map[string]string {
+	links := make(map[string]string, len(sortFields))
+	for _, field := range sortFields {
+		next := f
+		next.Sort = string(field)
+		next.Order = string(db.SortAsc)
+		if f.Sort == string(field) && f.Order != string(db.SortDesc) {
+			next.Order = string(db.SortDesc)
+		}
+		links[string(field)] = "/index?" + next.values().Encode()
+	}
+	return links
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListTodoFilterPageLink(t *testing.T) {
+	require.Empty(t, listTodoFilter{}.pageLink())
+	require.Empty(t, listTodoFilter{Query: "   "}.pageLink())
+
+	filter := listTodoFilter{
+		Query:       " dog ",
+		Sort:        "title",
+		Order:       "desc",
+		CreatedFrom: "2024-01-01",
+	}
+	link := filter.pageLink()
+	require.Equal(t, byte('&'), link[0])
+
+	values, err := url.ParseQuery(link[1:])
+	require.NoError(t, err)
+	require.Equal(t, "dog", values.Get("q"))
+	require.Equal(t, "title", values.Get("sort"))
+	require.Equal(t, "desc", values.Get("order"))
+	require.Equal(t, "2024-01-01", values.Get("created_from"))
+	require.NotContains(t, values, "page")
+	require.NotContains(t, values, "due_to")
+}
+
+func TestListTodoFilterSortLinks(t *testing.T) {
+	testCases := []struct {
+		name   string
+		filter listTodoFilter
+		field  string
+		order  string
+	}{
+		{
+			name:   "Unsorted column sorts ascending",
+			filter: listTodoFilter{Sort: "title", Order: "desc"},
+			field:  "create_date",
+			order:  "asc",
+		},
+		{
+			name:   "Ascending column flips to descending",
+			filter: listTodoFilter{Sort: "title", Order: "asc"},
+			field:  "title",
+			order:  "desc",
+		},
+		{
+			name:   "Descending column flips to ascending",
+			filter: listTodoFilter{Sort: "title", Order: "desc"},
+			field:  "title",
+			order:  "asc",
+		},
+		{
+			name:   "Default order is ascending",
+			filter: listTodoFilter{Sort: "id"},
+			field:  "id",
+			order:  "desc",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			tc.filter.Query = "dog"
+			link, err := url.Parse(tc.filter.sortLinks()[tc.field])
+			require.NoError(t, err)
+			require.Equal(t, "/index", link.Path)
+
+			values := link.Query()
+			require.Equal(t, tc.field, values.Get("sort"))
+			require.Equal(t, tc.order, values.Get("order"))
+			require.Equal(t, "dog", values.Get("q"))
+		})
+	}
+}
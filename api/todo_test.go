@@ -9,17 +9,22 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
 
 func TestListUpTodoAPI(t *testing.T) {
+	user := randomUser(t)
 	testCases := []struct {
 		name          string
 		page          string
+		query         string
+		params        string
 		buildStubs    func(repo *mockdb.MockRepo)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
@@ -27,22 +32,231 @@ func TestListUpTodoAPI(t *testing.T) {
 			name: "OK",
 			page: "1",
 			buildStubs: func(repo *mockdb.MockRepo) {
+				todoList := []db.Todo{randomTodo(user), randomTodo(user)}
+
 				repo.EXPECT().
-					CountTodo(gomock.Any()).
+					CountTodo(gomock.Any(), gomock.Eq(db.TodoFilter{UserID: user.ID})).
 					Times(1).
 					Return(int64(5), nil)
 
 				arg := db.ListTodoParams{
-					Offset: 0,
-					Limit:  5,
+					TodoFilter: db.TodoFilter{UserID: user.ID},
+					Offset:     0,
+					Limit:      5,
+				}
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(todoList, nil)
+
+				tagArg := db.ListTagsByTodoIDsParams{
+					UserID:  user.ID,
+					TodoIDs: []int64{todoList[0].ID, todoList[1].ID},
+				}
+				repo.EXPECT().
+					ListTagsByTodoIDs(gomock.Any(), gomock.Eq(tagArg)).
+					Times(1).
+					Return(map[int64][]db.Tag{todoList[0].ID: {{ID: 1, UserID: user.ID, Name: "project-x"}}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "/index?tag=project-x")
+			},
+		},
+		{
+			name:  "OK with search",
+			page:  "2",
+			query: "dog",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Eq(db.TodoFilter{UserID: user.ID, Query: "dog"})).
+					Times(1).
+					Return(int64(12), nil)
+
+				arg := db.ListTodoParams{
+					TodoFilter: db.TodoFilter{UserID: user.ID, Query: "dog"},
+					Offset:     5,
+					Limit:      5,
 				}
 				repo.EXPECT().
 					ListTodo(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return([]db.Todo{}, nil)
+
+				repo.EXPECT().
+					ListTagsByTodoIDs(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(map[int64][]db.Tag{}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "/index?page&#61;3&amp;q&#61;dog")
+			},
+		},
+		{
+			name:   "OK with sort and filter",
+			page:   "1",
+			params: "sort=title&order=desc&created_from=2024-01-01&created_to=2024-01-31",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				filter := db.TodoFilter{
+					UserID:      user.ID,
+					CreatedFrom: sql.NullTime{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local), Valid: true},
+					CreatedTo:   sql.NullTime{Time: time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local), Valid: true},
+				}
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Eq(filter)).
+					Times(1).
+					Return(int64(5), nil)
+
+				arg := db.ListTodoParams{
+					TodoFilter: filter,
+					SortBy:     db.TodoSortTitle,
+					SortOrder:  db.SortDesc,
+					Offset:     0,
+					Limit:      5,
+				}
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.Todo{}, nil)
+
+				repo.EXPECT().
+					ListTagsByTodoIDs(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(map[int64][]db.Tag{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "fa fa-sort-desc")
+			},
+		},
+		{
+			name:   "Per Page",
+			page:   "2",
+			params: "per_page=10",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Eq(db.TodoFilter{UserID: user.ID})).
+					Times(1).
+					Return(int64(25), nil)
+
+				arg := db.ListTodoParams{
+					TodoFilter: db.TodoFilter{UserID: user.ID},
+					Offset:     10,
+					Limit:      10,
+				}
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.Todo{}, nil)
+
+				repo.EXPECT().
+					ListTagsByTodoIDs(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(map[int64][]db.Tag{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				body := recorder.Body.String()
+				require.Contains(t, body, `<link rel="prev" href=/index?page&#61;1&amp;per_page&#61;10>`)
+				require.Contains(t, body, `<link rel="next" href=/index?page&#61;3&amp;per_page&#61;10>`)
+				require.Contains(t, body, "/index?order&#61;asc&amp;sort&#61;title&amp;per_page&#61;10")
+			},
+		},
+		{
+			name:   "Per Page Over Max",
+			page:   "1",
+			params: "per_page=1000",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Eq(db.TodoFilter{UserID: user.ID})).
+					Times(1).
+					Return(int64(5), nil)
+
+				arg := db.ListTodoParams{
+					TodoFilter: db.TodoFilter{UserID: user.ID},
+					Offset:     0,
+					Limit:      100,
+				}
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.Todo{}, nil)
+
+				repo.EXPECT().
+					ListTagsByTodoIDs(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(map[int64][]db.Tag{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Invalid Per Page",
+			page:   "1",
+			params: "per_page=0",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "Page Out Of Range",
+			page:  "9",
+			query: "dog",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Eq(db.TodoFilter{UserID: user.ID, Query: "dog"})).
+					Times(1).
+					Return(int64(12), nil)
+
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+				require.Equal(t, "/index?page=3&q=dog", recorder.Header().Get("Location"))
+			},
+		},
+		{
+			name: "Negative Page",
+			page: "-1",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Eq(db.TodoFilter{UserID: user.ID})).
+					Times(1).
+					Return(int64(12), nil)
+
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+				require.Equal(t, "/index?page=1", recorder.Header().Get("Location"))
+			},
+		},
+		{
+			name:   "Invalid Sort",
+			page:   "1",
+			params: "sort=password",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
@@ -50,7 +264,7 @@ func TestListUpTodoAPI(t *testing.T) {
 			page: "abcde",
 			buildStubs: func(repo *mockdb.MockRepo) {
 				repo.EXPECT().
-					CountTodo(gomock.Any()).
+					CountTodo(gomock.Any(), gomock.Any()).
 					Times(0)
 
 				repo.EXPECT().
@@ -66,7 +280,7 @@ func TestListUpTodoAPI(t *testing.T) {
 			page: "1",
 			buildStubs: func(repo *mockdb.MockRepo) {
 				repo.EXPECT().
-					CountTodo(gomock.Any()).
+					CountTodo(gomock.Any(), gomock.Eq(db.TodoFilter{UserID: user.ID})).
 					Times(1).
 					Return(int64(0), sql.ErrConnDone)
 
@@ -83,7 +297,7 @@ func TestListUpTodoAPI(t *testing.T) {
 			page: "1",
 			buildStubs: func(repo *mockdb.MockRepo) {
 				repo.EXPECT().
-					CountTodo(gomock.Any()).
+					CountTodo(gomock.Any(), gomock.Eq(db.TodoFilter{UserID: user.ID})).
 					Times(1).
 					Return(int64(5), nil)
 
@@ -112,9 +326,16 @@ func TestListUpTodoAPI(t *testing.T) {
 			recorder := httptest.NewRecorder()
 
 			targetUrl := fmt.Sprintf("/index?page=%s", tc.page)
+			if len(tc.query) > 0 {
+				targetUrl += "&q=" + url.QueryEscape(tc.query)
+			}
+			if len(tc.params) > 0 {
+				targetUrl += "&" + tc.params
+			}
 			request, err := http.NewRequest(http.MethodGet, targetUrl, nil)
 			require.NoError(t, err)
 
+			addAuthSession(t, server, request, user)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -122,7 +343,8 @@ func TestListUpTodoAPI(t *testing.T) {
 }
 
 func TestShowTodoAPI(t *testing.T) {
-	todo := randomTodo()
+	user := randomUser(t)
+	todo := randomTodo(user)
 
 	testCases := []struct {
 		name          string
@@ -135,12 +357,36 @@ func TestShowTodoAPI(t *testing.T) {
 			todoID: todo.ID,
 			buildStubs: func(repo *mockdb.MockRepo) {
 				repo.EXPECT().
-					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
 					Times(1).
 					Return(todo, nil)
+
+				repo.EXPECT().
+					ListTagsByTodo(gomock.Any(), gomock.Eq(db.ListTagsByTodoParams{TodoID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return([]db.Tag{{ID: 1, UserID: user.ID, Name: "project-x"}}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "project-x")
+			},
+		},
+		{
+			name:   "Tags Internal Error",
+			todoID: todo.ID,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(todo, nil)
+
+				repo.EXPECT().
+					ListTagsByTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
 		{
@@ -148,7 +394,7 @@ func TestShowTodoAPI(t *testing.T) {
 			todoID: todo.ID,
 			buildStubs: func(repo *mockdb.MockRepo) {
 				repo.EXPECT().
-					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
 					Times(1).
 					Return(db.Todo{}, sql.ErrNoRows)
 			},
@@ -161,7 +407,7 @@ func TestShowTodoAPI(t *testing.T) {
 			todoID: todo.ID,
 			buildStubs: func(repo *mockdb.MockRepo) {
 				repo.EXPECT().
-					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
 					Times(1).
 					Return(db.Todo{}, sql.ErrConnDone)
 			},
@@ -202,6 +448,7 @@ func TestShowTodoAPI(t *testing.T) {
 			request, err := http.NewRequest(http.MethodGet, targetUrl, nil)
 			require.NoError(t, err)
 
+			addAuthSession(t, server, request, user)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -209,6 +456,7 @@ func TestShowTodoAPI(t *testing.T) {
 }
 
 func TestNewTodoAPI(t *testing.T) {
+	user := randomUser(t)
 	testCases := []struct {
 		name          string
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
@@ -237,6 +485,7 @@ func TestNewTodoAPI(t *testing.T) {
 			request, err := http.NewRequest(http.MethodGet, targetUrl, nil)
 			require.NoError(t, err)
 
+			addAuthSession(t, server, request, user)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -244,7 +493,8 @@ func TestNewTodoAPI(t *testing.T) {
 }
 
 func TestCreateTodoAPI(t *testing.T) {
-	todo := randomTodo()
+	user := randomUser(t)
+	todo := randomTodo(user)
 
 	testCases := []struct {
 		name          string
@@ -259,20 +509,69 @@ func TestCreateTodoAPI(t *testing.T) {
 				Description: todo.Description.String,
 			},
 			buildStubs: func(repo *mockdb.MockRepo) {
-				arg := db.CreateTodoParams{
-					Title:       todo.Title,
-					Description: todo.Description,
+				arg := db.CreateTodoTxParams{
+					CreateTodoParams: db.CreateTodoParams{
+						UserID:      user.ID,
+						Title:       todo.Title,
+						Description: todo.Description,
+						Priority:    db.TodoPriorityMedium,
+					},
+					TagNames: []string{},
+				}
+
+				repo.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CreateTodoTxResult{Todo: todo}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+				require.Equal(t, fmt.Sprintf("/show?id=%d", todo.ID), recorder.Header().Get("Location"))
+			},
+		},
+		{
+			name: "OK with tags",
+			body: createTodoRequest{
+				Title:       todo.Title.String,
+				Description: todo.Description.String,
+				Tags:        " work, urgent,work ,",
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				arg := db.CreateTodoTxParams{
+					CreateTodoParams: db.CreateTodoParams{
+						UserID:      user.ID,
+						Title:       todo.Title,
+						Description: todo.Description,
+						Priority:    db.TodoPriorityMedium,
+					},
+					TagNames: []string{"work", "urgent"},
 				}
 
 				repo.EXPECT().
-					CreateTodo(gomock.Any(), gomock.Eq(arg)).
+					CreateTodoTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(MockSqlReturn{}, nil)
+					Return(db.CreateTodoTxResult{Todo: todo}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusFound, recorder.Code)
 			},
 		},
+		{
+			name: "Invalid Tag",
+			body: createTodoRequest{
+				Title:       todo.Title.String,
+				Description: todo.Description.String,
+				Tags:        strings.Repeat("a", 65),
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "Internal Error",
 			body: createTodoRequest{
@@ -281,9 +580,9 @@ func TestCreateTodoAPI(t *testing.T) {
 			},
 			buildStubs: func(repo *mockdb.MockRepo) {
 				repo.EXPECT().
-					CreateTodo(gomock.Any(), gomock.Any()).
+					CreateTodoTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(MockSqlReturn{}, sql.ErrConnDone)
+					Return(db.CreateTodoTxResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -307,12 +606,14 @@ func TestCreateTodoAPI(t *testing.T) {
 			data := url.Values{}
 			data.Set("titleInput", tc.body.Title)
 			data.Set("descriptionInput", tc.body.Description)
+			data.Set("tagsInput", tc.body.Tags)
 
 			targetUrl := "/new"
 			request, err := http.NewRequest(http.MethodPost, targetUrl, strings.NewReader(data.Encode()))
 			request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 			require.NoError(t, err)
 
+			addAuthSession(t, server, request, user)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -320,7 +621,8 @@ func TestCreateTodoAPI(t *testing.T) {
 }
 
 func TestEditTodoAPI(t *testing.T) {
-	todo := randomTodo()
+	user := randomUser(t)
+	todo := randomTodo(user)
 
 	testCases := []struct {
 		name          string
@@ -333,7 +635,7 @@ func TestEditTodoAPI(t *testing.T) {
 			todoID: todo.ID,
 			buildStubs: func(repo *mockdb.MockRepo) {
 				repo.EXPECT().
-					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
 					Times(1).
 					Return(todo, nil)
 			},
@@ -346,7 +648,7 @@ func TestEditTodoAPI(t *testing.T) {
 			todoID: todo.ID,
 			buildStubs: func(repo *mockdb.MockRepo) {
 				repo.EXPECT().
-					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
 					Times(1).
 					Return(todo, sql.ErrConnDone)
 			},
@@ -359,7 +661,7 @@ func TestEditTodoAPI(t *testing.T) {
 			todoID: 0,
 			buildStubs: func(repo *mockdb.MockRepo) {
 				repo.EXPECT().
-					GetTodo(gomock.Any(), gomock.Eq(todo.ID)).
+					GetTodo(gomock.Any(), gomock.Eq(db.GetTodoParams{ID: todo.ID, UserID: user.ID})).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -385,6 +687,7 @@ func TestEditTodoAPI(t *testing.T) {
 			request, err := http.NewRequest(http.MethodGet, targetUrl, nil)
 			require.NoError(t, err)
 
+			addAuthSession(t, server, request, user)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -392,7 +695,8 @@ func TestEditTodoAPI(t *testing.T) {
 }
 
 func TestUpdateTodoAPI(t *testing.T) {
-	todo := randomTodo()
+	user := randomUser(t)
+	todo := randomTodo(user)
 
 	testCases := []struct {
 		name          string
@@ -404,12 +708,19 @@ func TestUpdateTodoAPI(t *testing.T) {
 			name: "OK",
 			body: updateTodoRequest{
 				Description: todo.Description.String,
+				Status:      db.TodoStatusDone,
+				Priority:    db.TodoPriorityHigh,
+				DueAt:       "2030-01-02",
 				ID:          todo.ID,
 			},
 			buildStubs: func(repo *mockdb.MockRepo) {
 				arg := db.UpdateTodoParams{
 					Description: todo.Description,
+					Status:      db.TodoStatusDone,
+					Priority:    db.TodoPriorityHigh,
+					DueAt:       dateFromForm("2030-01-02"),
 					ID:          todo.ID,
+					UserID:      user.ID,
 				}
 
 				repo.EXPECT().
@@ -424,12 +735,18 @@ func TestUpdateTodoAPI(t *testing.T) {
 		{
 			name: "Bad Request",
 			body: updateTodoRequest{
-				ID: todo.ID,
+				Status:   db.TodoStatusDone,
+				Priority: db.TodoPriorityHigh,
+				ID:       todo.ID,
 			},
 			buildStubs: func(repo *mockdb.MockRepo) {
 				arg := db.UpdateTodoParams{
 					Description: todo.Description,
+					Status:      db.TodoStatusDone,
+					Priority:    db.TodoPriorityHigh,
+					DueAt:       dateFromForm("2030-01-02"),
 					ID:          todo.ID,
+					UserID:      user.ID,
 				}
 
 				repo.EXPECT().
@@ -444,12 +761,19 @@ func TestUpdateTodoAPI(t *testing.T) {
 			name: "Internal Error",
 			body: updateTodoRequest{
 				Description: todo.Description.String,
+				Status:      db.TodoStatusDone,
+				Priority:    db.TodoPriorityHigh,
+				DueAt:       "2030-01-02",
 				ID:          todo.ID,
 			},
 			buildStubs: func(repo *mockdb.MockRepo) {
 				arg := db.UpdateTodoParams{
 					Description: todo.Description,
+					Status:      db.TodoStatusDone,
+					Priority:    db.TodoPriorityHigh,
+					DueAt:       dateFromForm("2030-01-02"),
 					ID:          todo.ID,
+					UserID:      user.ID,
 				}
 
 				repo.EXPECT().
@@ -478,6 +802,9 @@ func TestUpdateTodoAPI(t *testing.T) {
 
 			data := url.Values{}
 			data.Set("descriptionInput", tc.body.Description)
+			data.Set("statusInput", string(tc.body.Status))
+			data.Set("priorityInput", string(tc.body.Priority))
+			data.Set("dueAtInput", tc.body.DueAt)
 			data.Set("id", fmt.Sprintf("%d", tc.body.ID))
 
 			targetUrl := "/edit"
@@ -485,6 +812,91 @@ func TestUpdateTodoAPI(t *testing.T) {
 			request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 			require.NoError(t, err)
 
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestToggleTodoAPI(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+
+	testCases := []struct {
+		name          string
+		todoID        int64
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			todoID: todo.ID,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				arg := db.ToggleTodoParams{
+					ID:     todo.ID,
+					UserID: user.ID,
+				}
+
+				repo.EXPECT().
+					ToggleTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+				require.Equal(t, "/index", recorder.Header().Get("Location"))
+			},
+		},
+		{
+			name:   "Bad Request",
+			todoID: 0,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					ToggleTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "Internal Error",
+			todoID: todo.ID,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					ToggleTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			data := url.Values{}
+			data.Set("id", fmt.Sprintf("%d", tc.todoID))
+
+			targetUrl := "/toggle"
+			request, err := http.NewRequest(http.MethodPost, targetUrl, strings.NewReader(data.Encode()))
+			request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -492,6 +904,7 @@ func TestUpdateTodoAPI(t *testing.T) {
 }
 
 func TestDeleteTodoAPI(t *testing.T) {
+	user := randomUser(t)
 	dummyDeleteReq := deleteTodoRequest{
 		ID:     1,
 		IDList: "1,2,3",
@@ -510,12 +923,12 @@ func TestDeleteTodoAPI(t *testing.T) {
 			},
 			buildStubs: func(repo *mockdb.MockRepo) {
 				repo.EXPECT().
-					DeleteTodo(gomock.Any(), gomock.Eq(dummyDeleteReq.ID)).
+					DeleteTodo(gomock.Any(), gomock.Eq(db.DeleteTodoParams{ID: dummyDeleteReq.ID, UserID: user.ID})).
 					Times(1).
 					Return(nil)
 
 				repo.EXPECT().
-					DeleteTodoList(gomock.Any(), gomock.Any()).
+					DeleteTodoListTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -532,15 +945,77 @@ func TestDeleteTodoAPI(t *testing.T) {
 					DeleteTodo(gomock.Any(), gomock.Any()).
 					Times(0)
 
+				arg := db.DeleteTodoListParams{UserID: user.ID, IDs: []int64{1, 2, 3}}
 				repo.EXPECT().
-					DeleteTodoList(gomock.Any(), gomock.Eq(dummyDeleteReq.IDList)).
+					DeleteTodoListTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(nil)
+					Return(db.DeleteTodoListTxResult{Deleted: 2, NotFound: []int64{3}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+				require.NotEmpty(t, recorder.Header().Get("Set-Cookie"))
+			},
+		},
+		{
+			name: "OK with duplicated IDs",
+			body: deleteTodoRequest{
+				IDList: "3, 1,3",
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				arg := db.DeleteTodoListParams{UserID: user.ID, IDs: []int64{3, 1}}
+				repo.EXPECT().
+					DeleteTodoListTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.DeleteTodoListTxResult{Deleted: 2, NotFound: []int64{}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid List",
+			body: deleteTodoRequest{
+				IDList: "1,abc",
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					DeleteTodoListTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Duplicates Below Cap",
+			body: deleteTodoRequest{
+				IDList: strings.Repeat("1,", maxBulkDeleteSize) + "2,3",
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					DeleteTodoListTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DeleteTodoListTxResult{Deleted: 3, NotFound: []int64{}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				// Duplicates do not count towards the cap.
 				require.Equal(t, http.StatusFound, recorder.Code)
 			},
 		},
+		{
+			name: "Too Many IDs",
+			body: deleteTodoRequest{
+				IDList: idListOfSize(maxBulkDeleteSize + 1),
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					DeleteTodoListTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "Internal Error 1",
 			body: deleteTodoRequest{
@@ -548,12 +1023,12 @@ func TestDeleteTodoAPI(t *testing.T) {
 			},
 			buildStubs: func(repo *mockdb.MockRepo) {
 				repo.EXPECT().
-					DeleteTodo(gomock.Any(), gomock.Eq(dummyDeleteReq.ID)).
+					DeleteTodo(gomock.Any(), gomock.Eq(db.DeleteTodoParams{ID: dummyDeleteReq.ID, UserID: user.ID})).
 					Times(1).
 					Return(sql.ErrConnDone)
 
 				repo.EXPECT().
-					DeleteTodoList(gomock.Any(), gomock.Any()).
+					DeleteTodoListTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -571,9 +1046,9 @@ func TestDeleteTodoAPI(t *testing.T) {
 					Times(0)
 
 				repo.EXPECT().
-					DeleteTodoList(gomock.Any(), gomock.Eq(dummyDeleteReq.IDList)).
+					DeleteTodoListTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(sql.ErrConnDone)
+					Return(db.DeleteTodoListTxResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -603,17 +1078,93 @@ func TestDeleteTodoAPI(t *testing.T) {
 			request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 			require.NoError(t, err)
 
+			addAuthSession(t, server, request, user)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
 	}
 }
 
-func randomTodo() db.Todo {
+func TestParseIDList(t *testing.T) {
+	ids, err := parseIDList(" 4,2 ,4")
+	require.NoError(t, err)
+	require.Equal(t, []int64{4, 2}, ids)
+
+	for _, value := range []string{"1,abc", "1,,2", "0", "-3", "1.5", idListOfSize(maxBulkDeleteSize + 1)} {
+		_, err := parseIDList(value)
+		require.ErrorIs(t, err, errInvalidIDList, value)
+	}
+
+	_, err = parseIDList(idListOfSize(maxBulkDeleteSize))
+	require.NoError(t, err)
+}
+
+func TestBulkDeleteFlash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := randomUser(t)
+	repo := mockdb.NewMockRepo(ctrl)
+	repo.EXPECT().
+		DeleteTodoListTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.DeleteTodoListTxResult{Deleted: 1, NotFound: []int64{7, 9}}, nil)
+	repo.EXPECT().
+		CountTodo(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(int64(0), nil)
+	repo.EXPECT().
+		ListTodo(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.Todo{}, nil)
+	repo.EXPECT().
+		ListTagsByTodoIDs(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(map[int64][]db.Tag{}, nil)
+
+	server := newTestServer(repo)
+
+	data := url.Values{}
+	data.Set("ids", "5,7,9")
+	request, err := http.NewRequest(http.MethodPost, "/delete", strings.NewReader(data.Encode()))
+	require.NoError(t, err)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	addAuthSession(t, server, request, user)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusFound, recorder.Code)
+
+	// The flash lives on the session cookie returned by the delete.
+	request, err = http.NewRequest(http.MethodGet, "/index", nil)
+	require.NoError(t, err)
+	for _, cookie := range recorder.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), "Moved 1 todos to the trash. Not found: 7, 9.")
+}
+
+// idListOfSize returns a comma separated list of n distinct IDs.
+func idListOfSize(n int) string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i + 1)
+	}
+	return strings.Join(ids, ",")
+}
+
+func randomTodo(user db.User) db.Todo {
 	return db.Todo{
 		ID:          util.RandomInt(1, 1000),
 		Title:       util.RandomTitle(),
 		Description: util.RandomDescription(),
+		UserID:      user.ID,
+		Status:      db.TodoStatusOpen,
+		Priority:    db.TodoPriorityMedium,
 	}
 }
 
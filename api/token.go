@@ -0,0 +1,243 @@
+package api
+
+import (
+	"database/sql"
+	db "first-app/todo_go/db/sqlc"
+	"first-app/todo_go/util"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	csrf "github.com/utrack/gin-csrf"
+)
+
+const (
+	scopeRead  = "read"
+	scopeWrite = "write"
+
+	authorizationHeaderKey  = "Authorization"
+	authorizationTypeBearer = "bearer"
+	tokenAuthKey            = "tokenAuth"
+)
+
+var tokenScopes = []string{scopeRead, scopeWrite}
+
+// requiredScope returns the scope a token needs for the request method.
+func requiredScope(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return scopeRead
+	default:
+		return scopeWrite
+	}
+}
+
+func hasScope(scopes string, scope string) bool {
+	for _, s := range strings.Split(scopes, ",") {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenAuthMiddleware authenticates requests carrying a personal access token
+// in the Authorization header. Requests without the header pass through
+// untouched so the session based authMiddleware can handle them.
+func (server *Server) tokenAuthMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
+		if len(authorizationHeader) == 0 {
+			ctx.Next()
+			return
+		}
+
+		fields := strings.Fields(authorizationHeader)
+		if len(fields) != 2 || strings.ToLower(fields[0]) != authorizationTypeBearer {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse("unauthorized", "invalid authorization header format"))
+			return
+		}
+
+		token, dbErr := server.repo.GetAccessTokenByHash(ctx, util.HashAccessToken(fields[1]))
+		if dbErr != nil {
+			if dbErr == sql.ErrNoRows {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse("unauthorized", "invalid access token"))
+				return
+			}
+
+			logError(ctx, util.ErrorDetails{
+				Cause:    "Error occurred when fetching the data from DB",
+				Effect:   "User can see 500 Internal Server Error response",
+				Solution: "This might be the database connection issue, please check the database status",
+				Position: "token.go file, tokenAuthMiddleware method on GetAccessTokenByHash query",
+			}, dbErr)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse("internal_error", "failed to verify access token"))
+			return
+		}
+
+		if token.RevokedAt.Valid {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse("unauthorized", "access token has been revoked"))
+			return
+		}
+		if token.ExpiresAt.Valid && server.now().After(token.ExpiresAt.Time) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse("unauthorized", "access token has expired"))
+			return
+		}
+		if scope := requiredScope(ctx.Request.Method); !hasScope(token.Scopes, scope) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse("insufficient_scope", "access token lacks the "+scope+" scope"))
+			return
+		}
+
+		if dbErr := server.repo.TouchAccessToken(ctx, token.ID); dbErr != nil {
+			logError(ctx, util.ErrorDetails{
+				Cause:    "Error occurred when updating the token usage on DB",
+				Effect:   "The last used date of the token is not updated, the request continues",
+				Solution: "This might be the database connection issue, please check the database status",
+				Position: "token.go file, tokenAuthMiddleware method on TouchAccessToken query",
+			}, dbErr)
+		}
+
+		ctx.Set(authUserKey, authUser{ID: token.UserID})
+		addLogAttrs(ctx, slog.Int64("user_id", token.UserID))
+		ctx.Set(tokenAuthKey, true)
+		ctx.Next()
+	}
+}
+
+// csrfMiddleware verifies the CSRF token of every request except the ones
+// authenticated by tokenAuthMiddleware, which carry no session to protect.
+func csrfMiddleware(options csrf.Options) gin.HandlerFunc {
+	verify := csrf.Middleware(options)
+	return func(ctx *gin.Context) {
+		if ctx.GetBool(tokenAuthKey) {
+			ctx.Next()
+			return
+		}
+		verify(ctx)
+	}
+}
+
+func (server *Server) renderTokens(ctx *gin.Context, status int, data gin.H) {
+	user := currentUser(ctx)
+	tokens, dbErr := server.repo.ListAccessTokens(ctx, user.ID)
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the data from DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "token.go file, renderTokens method on ListAccessTokens query",
+		}, dbErr)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
+	data["tokens"] = tokens
+	data["scopes"] = tokenScopes
+	data["user"] = user
+	data["token"] = csrf.GetToken(ctx)
+	ctx.HTML(status, "tokens.html", data)
+}
+
+func (server *Server) listTokens(ctx *gin.Context) {
+	server.renderTokens(ctx, http.StatusOK, gin.H{})
+}
+
+type createTokenRequest struct {
+	Name          string   `form:"name" binding:"required,max=255"`
+	Scopes        []string `form:"scopes" binding:"required,dive,oneof=read write"`
+	ExpiresInDays int      `form:"expiresInDays" binding:"min=0,max=365"`
+}
+
+func (server *Server) createToken(ctx *gin.Context) {
+	var req createTokenRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User sent invalid token data",
+			Effect:   "User can see the token page again with an error message",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "token.go file, createToken method",
+		}, err)
+		server.renderTokens(ctx, http.StatusBadRequest, gin.H{
+			"error": "A token needs a name and at least one scope.",
+		})
+		return
+	}
+
+	plainToken, err := util.GenerateAccessToken()
+	if err != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when generating the access token",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the random source issue, please check the server status",
+			Position: "token.go file, createToken method on GenerateAccessToken",
+		}, err)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
+	var expiresAt sql.NullTime
+	if req.ExpiresInDays > 0 {
+		expiresAt = sql.NullTime{Time: server.now().AddDate(0, 0, req.ExpiresInDays), Valid: true}
+	}
+
+	user := currentUser(ctx)
+	arg := db.CreateAccessTokenParams{
+		UserID:    user.ID,
+		Name:      req.Name,
+		TokenHash: util.HashAccessToken(plainToken),
+		Scopes:    strings.Join(req.Scopes, ","),
+		ExpiresAt: expiresAt,
+	}
+	if _, dbErr := server.repo.CreateAccessToken(ctx, arg); dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when inserting the data to DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "token.go file, createToken method on CreateAccessToken query",
+		}, dbErr)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
+	// The plain token is only shown once, it cannot be recovered from its hash.
+	server.renderTokens(ctx, http.StatusCreated, gin.H{
+		"newToken": plainToken,
+	})
+}
+
+type revokeTokenRequest struct {
+	ID int64 `form:"id" binding:"required,min=1"`
+}
+
+func (server *Server) revokeToken(ctx *gin.Context) {
+	var req revokeTokenRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid ID param",
+			Effect:   "User can see 400 Bad Request page",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "token.go file, revokeToken method",
+		}, err)
+		ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
+		return
+	}
+
+	user := currentUser(ctx)
+	arg := db.RevokeAccessTokenParams{
+		ID:     req.ID,
+		UserID: user.ID,
+	}
+	if dbErr := server.repo.RevokeAccessToken(ctx, arg); dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when updating the data on DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "token.go file, revokeToken method on RevokeAccessToken query",
+		}, dbErr)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, "/tokens")
+}
@@ -0,0 +1,500 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	mockdb "first-app/todo_go/db/mock"
+	db "first-app/todo_go/db/sqlc"
+	"first-app/todo_go/util"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenAuthMiddleware(t *testing.T) {
+	user := randomUser(t)
+	plainToken, err := util.GenerateAccessToken()
+	require.NoError(t, err)
+	tokenHash := util.HashAccessToken(plainToken)
+
+	testCases := []struct {
+		name          string
+		method        string
+		setupAuth     func(t *testing.T, request *http.Request)
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			method: http.MethodGet,
+			setupAuth: func(t *testing.T, request *http.Request) {
+				addBearerToken(request, plainToken)
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				token := randomAccessToken(user, tokenHash, scopeRead)
+				repo.EXPECT().
+					GetAccessTokenByHash(gomock.Any(), gomock.Eq(tokenHash)).
+					Times(1).
+					Return(token, nil)
+				repo.EXPECT().
+					TouchAccessToken(gomock.Any(), gomock.Eq(token.ID)).
+					Times(1).
+					Return(nil)
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Eq(db.TodoFilter{UserID: user.ID})).
+					Times(1).
+					Return(int64(0), nil)
+				repo.EXPECT().
+					ListTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Todo{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Insufficient Scope",
+			method: http.MethodPost,
+			setupAuth: func(t *testing.T, request *http.Request) {
+				addBearerToken(request, plainToken)
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetAccessTokenByHash(gomock.Any(), gomock.Eq(tokenHash)).
+					Times(1).
+					Return(randomAccessToken(user, tokenHash, scopeRead), nil)
+				repo.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder.Body, "insufficient_scope")
+			},
+		},
+		{
+			name:   "Expired",
+			method: http.MethodGet,
+			setupAuth: func(t *testing.T, request *http.Request) {
+				addBearerToken(request, plainToken)
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				token := randomAccessToken(user, tokenHash, scopeRead)
+				token.ExpiresAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+				repo.EXPECT().
+					GetAccessTokenByHash(gomock.Any(), gomock.Eq(tokenHash)).
+					Times(1).
+					Return(token, nil)
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorCode(t, recorder.Body, "unauthorized")
+			},
+		},
+		{
+			name:   "Revoked",
+			method: http.MethodGet,
+			setupAuth: func(t *testing.T, request *http.Request) {
+				addBearerToken(request, plainToken)
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				token := randomAccessToken(user, tokenHash, scopeRead)
+				token.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				repo.EXPECT().
+					GetAccessTokenByHash(gomock.Any(), gomock.Eq(tokenHash)).
+					Times(1).
+					Return(token, nil)
+				repo.EXPECT().
+					CountTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorCode(t, recorder.Body, "unauthorized")
+			},
+		},
+		{
+			name:   "Unknown Token",
+			method: http.MethodGet,
+			setupAuth: func(t *testing.T, request *http.Request) {
+				addBearerToken(request, plainToken)
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetAccessTokenByHash(gomock.Any(), gomock.Eq(tokenHash)).
+					Times(1).
+					Return(db.AccessToken{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorCode(t, recorder.Body, "unauthorized")
+			},
+		},
+		{
+			name:   "Invalid Header Format",
+			method: http.MethodGet,
+			setupAuth: func(t *testing.T, request *http.Request) {
+				request.Header.Set(authorizationHeaderKey, "Basic "+plainToken)
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetAccessTokenByHash(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorCode(t, recorder.Body, "unauthorized")
+			},
+		},
+		{
+			name:   "Internal Error",
+			method: http.MethodGet,
+			setupAuth: func(t *testing.T, request *http.Request) {
+				addBearerToken(request, plainToken)
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetAccessTokenByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccessToken{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder.Body, "internal_error")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			targetUrl := "/api/v1/todos"
+			request, err := http.NewRequest(tc.method, targetUrl, bytes.NewReader([]byte("{}")))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			tc.setupAuth(t, request)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestTokenAuthBypassesCSRF(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+	plainToken, err := util.GenerateAccessToken()
+	require.NoError(t, err)
+	tokenHash := util.HashAccessToken(plainToken)
+
+	body, err := json.Marshal(gin.H{
+		"title":       todo.Title.String,
+		"description": todo.Description.String,
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, server *Server, request *http.Request)
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Token skips CSRF",
+			setupAuth: func(t *testing.T, server *Server, request *http.Request) {
+				addBearerToken(request, plainToken)
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				token := randomAccessToken(user, tokenHash, scopeRead+","+scopeWrite)
+				repo.EXPECT().
+					GetAccessTokenByHash(gomock.Any(), gomock.Eq(tokenHash)).
+					Times(1).
+					Return(token, nil)
+				repo.EXPECT().
+					TouchAccessToken(gomock.Any(), gomock.Eq(token.ID)).
+					Times(1).
+					Return(nil)
+				repo.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateTodoTxResult{Todo: todo}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "Session with CSRF token",
+			setupAuth: func(t *testing.T, server *Server, request *http.Request) {
+				addAuthSession(t, server, request, user)
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateTodoTxResult{Todo: todo}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:      "No credentials",
+			setupAuth: func(t *testing.T, server *Server, request *http.Request) {},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorCode(t, recorder.Body, "unauthorized")
+			},
+		},
+		{
+			name: "Session still needs CSRF",
+			setupAuth: func(t *testing.T, server *Server, request *http.Request) {
+				addAuthSession(t, server, request, user)
+				request.Header.Del("X-CSRF-TOKEN")
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CreateTodoTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/api/v1/todos", bytes.NewReader(body))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			tc.setupAuth(t, server, request)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateTokenAPI(t *testing.T) {
+	user := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          url.Values
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Created",
+			body: url.Values{
+				"name":          {"ci bot"},
+				"scopes":        {scopeRead, scopeWrite},
+				"expiresInDays": {"30"},
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CreateAccessToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateAccessTokenParams) (sql.Result, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, "ci bot", arg.Name)
+						require.Equal(t, "read,write", arg.Scopes)
+						require.Len(t, arg.TokenHash, 64)
+						require.True(t, arg.ExpiresAt.Valid)
+						require.WithinDuration(t, time.Now().AddDate(0, 0, 30), arg.ExpiresAt.Time, time.Minute)
+						return MockSqlReturn{}, nil
+					})
+				repo.EXPECT().
+					ListAccessTokens(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return([]db.AccessToken{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Contains(t, recorder.Body.String(), util.AccessTokenPrefix)
+			},
+		},
+		{
+			name: "Invalid Scope",
+			body: url.Values{
+				"name":   {"ci bot"},
+				"scopes": {"admin"},
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CreateAccessToken(gomock.Any(), gomock.Any()).
+					Times(0)
+				repo.EXPECT().
+					ListAccessTokens(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return([]db.AccessToken{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Internal Error",
+			body: url.Values{
+				"name":   {"ci bot"},
+				"scopes": {scopeRead},
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CreateAccessToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(MockSqlReturn{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			targetUrl := "/tokens"
+			request, err := http.NewRequest(http.MethodPost, targetUrl, strings.NewReader(tc.body.Encode()))
+			request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRevokeTokenAPI(t *testing.T) {
+	user := randomUser(t)
+	tokenID := util.RandomInt(1, 1000)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				arg := db.RevokeAccessTokenParams{
+					ID:     tokenID,
+					UserID: user.ID,
+				}
+				repo.EXPECT().
+					RevokeAccessToken(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+				require.Equal(t, "/tokens", recorder.Header().Get("Location"))
+			},
+		},
+		{
+			name: "Internal Error",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					RevokeAccessToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			data := url.Values{}
+			data.Set("id", fmt.Sprintf("%d", tokenID))
+
+			targetUrl := "/tokens/revoke"
+			request, err := http.NewRequest(http.MethodPost, targetUrl, strings.NewReader(data.Encode()))
+			request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomAccessToken(user db.User, tokenHash string, scopes string) db.AccessToken {
+	return db.AccessToken{
+		ID:        util.RandomInt(1, 1000),
+		UserID:    user.ID,
+		Name:      util.RandomString(6),
+		TokenHash: tokenHash,
+		Scopes:    scopes,
+		ExpiresAt: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+}
+
+func addBearerToken(request *http.Request, token string) {
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("Bearer %s", token))
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	db "first-app/todo_go/db/sqlc"
+	"first-app/todo_go/util"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	csrf "github.com/utrack/gin-csrf"
+)
+
+func (server *Server) listTrash(ctx *gin.Context) {
+	page, perPage, err := server.pageParams(ctx)
+	if err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid page or per_page param",
+			Effect:   "User can see 400 Bad Request page",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "trash.go file, listTrash method",
+		}, err)
+		ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
+		return
+	}
+
+	user := currentUser(ctx)
+	total, dbErr := server.repo.CountDeletedTodo(ctx, user.ID)
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the data from DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "trash.go file, listTrash method on CountDeletedTodo query",
+		}, dbErr)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
+	pageLink := server.perPageLink(perPage)
+	pageInfo := util.GetPageInfo(page, navLen, total, int64(perPage))
+	if pageInfo.Page != page {
+		ctx.Redirect(http.StatusFound, fmt.Sprintf("/trash?page=%d%s", pageInfo.Page, pageLink))
+		return
+	}
+
+	arg := db.ListDeletedTodoParams{
+		UserID: user.ID,
+		Offset: int32((page - 1) * perPage),
+		Limit:  int32(perPage),
+	}
+	todoList, dbErr := server.repo.ListDeletedTodo(ctx, arg)
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the data from DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "trash.go file, listTrash method on ListDeletedTodo query",
+		}, dbErr)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
+	ctx.HTML(http.StatusOK, "trash.html", gin.H{
+		"title":          "Trash",
+		"todoList":       todoList,
+		"token":          csrf.GetToken(ctx),
+		"pageInfo":       pageInfo,
+		"pagePath":       "/trash",
+		"perPage":        perPage,
+		"perPageOptions": server.perPageOptions("/trash", perPage, ""),
+		"pageLink":       pageLink,
+		"user":           user,
+	})
+}
+
+type trashTodoRequest struct {
+	ID int64 `form:"id" binding:"required,min=1"`
+}
+
+func (server *Server) restoreTodo(ctx *gin.Context) {
+	var req trashTodoRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid request data",
+			Effect:   "User can see 400 Bad Request page",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "trash.go file, restoreTodo method",
+		}, err)
+		ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
+		return
+	}
+	addLogAttrs(ctx, slog.Int64("todo_id", req.ID))
+
+	user := currentUser(ctx)
+	arg := db.RestoreTodoParams{
+		ID:     req.ID,
+		UserID: user.ID,
+	}
+	restored, dbErr := server.repo.RestoreTodo(ctx, arg)
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when updating the data on DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "trash.go file, restoreTodo method on RestoreTodo query",
+		}, dbErr)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+	if restored == 0 {
+		ctx.HTML(http.StatusNotFound, "500.html", gin.H{})
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, "/trash")
+}
+
+func (server *Server) purgeTodo(ctx *gin.Context) {
+	var req trashTodoRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User manipulated invalid request data",
+			Effect:   "User can see 400 Bad Request page",
+			Solution: "Request param tempered by client, no need to special issue handling",
+			Position: "trash.go file, purgeTodo method",
+		}, err)
+		ctx.HTML(http.StatusBadRequest, "400.html", gin.H{})
+		return
+	}
+	addLogAttrs(ctx, slog.Int64("todo_id", req.ID))
+
+	user := currentUser(ctx)
+	arg := db.PurgeTodoParams{
+		ID:     req.ID,
+		UserID: user.ID,
+	}
+	purged, dbErr := server.repo.PurgeTodo(ctx, arg)
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when deleting the data on DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "trash.go file, purgeTodo method on PurgeTodo query",
+		}, dbErr)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+	if purged == 0 {
+		ctx.HTML(http.StatusNotFound, "500.html", gin.H{})
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, "/trash")
+}
+
+// purgeTrash permanently deletes the todos of every user that have been in the
+// trash for longer than retention. The database works out the cutoff from its
+// own clock, the one that set deleted_at, so the time zone of the connection
+// does not shift it.
+func purgeTrash(ctx context.Context, repo db.Repo, retention time.Duration) {
+	purged, dbErr := repo.PurgeDeletedTodo(ctx, int64(retention/time.Second))
+	if dbErr != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when deleting the data on DB",
+			Effect:   "Trashed todos stay in the trash until the next purge",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "trash.go file, purgeTrash method on PurgeDeletedTodo query",
+		}, dbErr)
+		return
+	}
+	if purged > 0 {
+		loggerFrom(ctx).InfoContext(ctx, "Purged trashed todos",
+			slog.Int64("count", purged),
+			slog.Duration("retention", retention),
+		)
+	}
+}
+
+// RunTrashPurge purges the trash once right away and then on every interval
+// until ctx is done. A zero retention keeps trashed todos forever.
+func RunTrashPurge(ctx context.Context, repo db.Repo, retention, interval time.Duration) {
+	if retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	purgeTrash(ctx, repo, retention)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purgeTrash(ctx, repo, retention)
+		}
+	}
+}
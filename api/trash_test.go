@@ -0,0 +1,368 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	mockdb "first-app/todo_go/db/mock"
+	db "first-app/todo_go/db/sqlc"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListTrashAPI(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+	todo.DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	testCases := []struct {
+		name          string
+		page          string
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			page: "2",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountDeletedTodo(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(int64(6), nil)
+
+				arg := db.ListDeletedTodoParams{
+					UserID: user.ID,
+					Offset: 5,
+					Limit:  5,
+				}
+				repo.EXPECT().
+					ListDeletedTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.Todo{todo}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				body := recorder.Body.String()
+				require.Contains(t, body, todo.Title.String)
+				require.Contains(t, body, "/trash?page&#61;1")
+			},
+		},
+		{
+			name: "Per Page",
+			page: "1&per_page=20",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountDeletedTodo(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(int64(6), nil)
+
+				arg := db.ListDeletedTodoParams{
+					UserID: user.ID,
+					Offset: 0,
+					Limit:  20,
+				}
+				repo.EXPECT().
+					ListDeletedTodo(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.Todo{todo}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "/trash?page&#61;1&amp;per_page&#61;20")
+			},
+		},
+		{
+			name: "Page Out Of Range",
+			page: "5&per_page=2",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountDeletedTodo(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(int64(6), nil)
+
+				repo.EXPECT().
+					ListDeletedTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+				require.Equal(t, "/trash?page=3&per_page=2", recorder.Header().Get("Location"))
+			},
+		},
+		{
+			name: "Bad Request",
+			page: "abc",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountDeletedTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Internal Error",
+			page: "1",
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CountDeletedTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+
+				repo.EXPECT().
+					ListDeletedTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			targetUrl := fmt.Sprintf("/trash?page=%s", tc.page)
+			request, err := http.NewRequest(http.MethodGet, targetUrl, nil)
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRestoreTodoAPI(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+
+	testCases := []struct {
+		name          string
+		todoID        int64
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			todoID: todo.ID,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					RestoreTodo(gomock.Any(), gomock.Eq(db.RestoreTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+				require.Equal(t, "/trash", recorder.Header().Get("Location"))
+			},
+		},
+		{
+			name:   "Not Found",
+			todoID: todo.ID,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					RestoreTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "Bad Request",
+			todoID: 0,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					RestoreTodo(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "Internal Error",
+			todoID: todo.ID,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					RestoreTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			data := url.Values{}
+			data.Set("id", fmt.Sprintf("%d", tc.todoID))
+
+			targetUrl := "/trash/restore"
+			request, err := http.NewRequest(http.MethodPost, targetUrl, strings.NewReader(data.Encode()))
+			request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestPurgeTodoAPI(t *testing.T) {
+	user := randomUser(t)
+	todo := randomTodo(user)
+
+	testCases := []struct {
+		name          string
+		todoID        int64
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			todoID: todo.ID,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					PurgeTodo(gomock.Any(), gomock.Eq(db.PurgeTodoParams{ID: todo.ID, UserID: user.ID})).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+				require.Equal(t, "/trash", recorder.Header().Get("Location"))
+			},
+		},
+		{
+			name:   "Not Found",
+			todoID: todo.ID,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					PurgeTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "Internal Error",
+			todoID: todo.ID,
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					PurgeTodo(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			data := url.Values{}
+			data.Set("id", fmt.Sprintf("%d", tc.todoID))
+
+			targetUrl := "/trash/purge"
+			request, err := http.NewRequest(http.MethodPost, targetUrl, strings.NewReader(data.Encode()))
+			request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			require.NoError(t, err)
+
+			addAuthSession(t, server, request, user)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockdb.NewMockRepo(ctrl)
+	repo.EXPECT().
+		PurgeDeletedTodo(gomock.Any(), gomock.Eq(int64(30*24*60*60))).
+		Times(1).
+		Return(int64(2), nil)
+
+	purgeTrash(context.Background(), repo, 30*24*time.Hour)
+}
+
+func TestRunTrashPurge(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := mockdb.NewMockRepo(ctrl)
+		repo.EXPECT().
+			PurgeDeletedTodo(gomock.Any(), gomock.Any()).
+			Times(0)
+
+		RunTrashPurge(context.Background(), repo, 0, time.Hour)
+	})
+
+	t.Run("Stops with context", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		repo := mockdb.NewMockRepo(ctrl)
+		repo.EXPECT().
+			PurgeDeletedTodo(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(context.Context, int64) (int64, error) {
+				cancel()
+				return 0, nil
+			})
+
+		RunTrashPurge(ctx, repo, time.Hour, time.Hour)
+	})
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	db "first-app/todo_go/db/sqlc"
+	"first-app/todo_go/util"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	csrf "github.com/utrack/gin-csrf"
+)
+
+const (
+	sessionName        = "mysession"
+	sessionUserIDKey   = "userID"
+	sessionUsernameKey = "username"
+	authUserKey        = "authUser"
+)
+
+// authUser is the logged-in user resolved from the session by authMiddleware.
+type authUser struct {
+	ID       int64
+	Username string
+}
+
+// currentUser returns the user stored in the context by authMiddleware.
+func currentUser(ctx *gin.Context) authUser {
+	return ctx.MustGet(authUserKey).(authUser)
+}
+
+// setAuthSession records the user as logged in on the session cookie.
+func setAuthSession(ctx *gin.Context, user db.User) error {
+	session := sessions.Default(ctx)
+	session.Set(sessionUserIDKey, user.ID)
+	session.Set(sessionUsernameKey, user.Username)
+	return session.Save()
+}
+
+// authMiddleware lets the request through only when the session carries a
+// logged-in user, otherwise it hands the request to unauthorized and aborts.
+// Requests already authenticated by tokenAuthMiddleware are let through as is.
+func authMiddleware(unauthorized gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, ok := ctx.Get(authUserKey); ok {
+			ctx.Next()
+			return
+		}
+
+		session := sessions.Default(ctx)
+		userID, ok := session.Get(sessionUserIDKey).(int64)
+		if !ok {
+			unauthorized(ctx)
+			ctx.Abort()
+			return
+		}
+		username, _ := session.Get(sessionUsernameKey).(string)
+
+		ctx.Set(authUserKey, authUser{ID: userID, Username: username})
+		addLogAttrs(ctx, slog.Int64("user_id", userID), slog.String("username", username))
+		ctx.Next()
+	}
+}
+
+func redirectToLogin(ctx *gin.Context) {
+	ctx.Redirect(http.StatusFound, "/login")
+}
+
+func unauthorizedJSON(ctx *gin.Context) {
+	ctx.JSON(http.StatusUnauthorized, errorResponse("unauthorized", "login required"))
+}
+
+func (server *Server) signupPage(ctx *gin.Context) {
+	ctx.HTML(http.StatusOK, "signup.html", gin.H{
+		"token": csrf.GetToken(ctx),
+	})
+}
+
+type signupRequest struct {
+	Username string `form:"username" binding:"required,alphanum,min=3,max=64"`
+	Password string `form:"password" binding:"required,min=8,max=72"`
+}
+
+func (server *Server) signup(ctx *gin.Context) {
+	var req signupRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		logWarn(ctx, util.ErrorDetails{
+			Cause:    "User sent invalid signup data",
+			Effect:   "User can see the signup page again with an error message",
+			Solution: "Username must be alphanumeric and the password at least 8 characters",
+			Position: "user.go file, signup method",
+		}, err)
+		ctx.HTML(http.StatusBadRequest, "signup.html", gin.H{
+			"token": csrf.GetToken(ctx),
+			"error": "Username must be 3-64 alphanumeric characters and the password 8-72 characters.",
+		})
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.Password)
+	if err != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when hashing the password",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be a bcrypt issue, please check the server status",
+			Position: "user.go file, signup method on HashPassword",
+		}, err)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
+	arg := db.CreateUserParams{
+		Username:       req.Username,
+		HashedPassword: hashedPassword,
+	}
+	result, dbErr := server.repo.CreateUser(ctx, arg)
+	if dbErr != nil {
+		if errors.Is(dbErr, db.ErrDuplicate) {
+			ctx.HTML(http.StatusConflict, "signup.html", gin.H{
+				"token": csrf.GetToken(ctx),
+				"error": "The username is already taken.",
+			})
+			return
+		}
+
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when inserting the data to DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "user.go file, signup method on CreateUser query",
+		}, dbErr)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+	createdId, _ := result.LastInsertId()
+
+	user := db.User{ID: createdId, Username: req.Username}
+	if err := setAuthSession(ctx, user); err != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when saving the session",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the session store issue, please check the cookie settings",
+			Position: "user.go file, signup method on setAuthSession",
+		}, err)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, "/index")
+}
+
+func (server *Server) loginPage(ctx *gin.Context) {
+	ctx.HTML(http.StatusOK, "login.html", gin.H{
+		"token": csrf.GetToken(ctx),
+	})
+}
+
+type loginRequest struct {
+	Username string `form:"username" binding:"required"`
+	Password string `form:"password" binding:"required"`
+}
+
+func (server *Server) login(ctx *gin.Context) {
+	invalidLogin := func() {
+		ctx.HTML(http.StatusUnauthorized, "login.html", gin.H{
+			"token": csrf.GetToken(ctx),
+			"error": "Invalid username or password.",
+		})
+	}
+
+	var req loginRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		invalidLogin()
+		return
+	}
+
+	user, dbErr := server.repo.GetUserByUsername(ctx, req.Username)
+	if dbErr != nil {
+		if dbErr == sql.ErrNoRows {
+			invalidLogin()
+			return
+		}
+
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when fetching the data from DB",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the database connection issue, please check the database status",
+			Position: "user.go file, login method on GetUserByUsername query",
+		}, dbErr)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
+	if err := util.CheckPassword(req.Password, user.HashedPassword); err != nil {
+		invalidLogin()
+		return
+	}
+
+	if err := setAuthSession(ctx, user); err != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when saving the session",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the session store issue, please check the cookie settings",
+			Position: "user.go file, login method on setAuthSession",
+		}, err)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, "/index")
+}
+
+func (server *Server) logout(ctx *gin.Context) {
+	session := sessions.Default(ctx)
+	session.Clear()
+	if err := session.Save(); err != nil {
+		logError(ctx, util.ErrorDetails{
+			Cause:    "Error occurred when clearing the session",
+			Effect:   "User can see 500 Internal Server Error page",
+			Solution: "This might be the session store issue, please check the cookie settings",
+			Position: "user.go file, logout method",
+		}, err)
+		ctx.HTML(http.StatusInternalServerError, "500.html", gin.H{})
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, "/login")
+}
@@ -0,0 +1,366 @@
+package api
+
+import (
+	"database/sql"
+	mockdb "first-app/todo_go/db/mock"
+	db "first-app/todo_go/db/sqlc"
+	"first-app/todo_go/util"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	csrf "github.com/utrack/gin-csrf"
+)
+
+const testPassword = "secret-password"
+
+func TestSignupAPI(t *testing.T) {
+	username := util.RandomUsername()
+
+	testCases := []struct {
+		name          string
+		body          signupRequest
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: signupRequest{
+				Username: username,
+				Password: testPassword,
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateUserParams) (sql.Result, error) {
+						require.Equal(t, username, arg.Username)
+						require.NoError(t, util.CheckPassword(testPassword, arg.HashedPassword))
+						return MockSqlReturn{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+				require.Equal(t, "/index", recorder.Header().Get("Location"))
+				require.NotEmpty(t, recorder.Result().Cookies())
+			},
+		},
+		{
+			name: "Bad Request",
+			body: signupRequest{
+				Username: "invalid-user#",
+				Password: "short",
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Duplicate Username",
+			body: signupRequest{
+				Username: username,
+				Password: testPassword,
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(MockSqlReturn{}, db.ErrDuplicate)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "Internal Error",
+			body: signupRequest{
+				Username: username,
+				Password: testPassword,
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(MockSqlReturn{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			data := url.Values{}
+			data.Set("username", tc.body.Username)
+			data.Set("password", tc.body.Password)
+
+			targetUrl := "/signup"
+			request, err := http.NewRequest(http.MethodPost, targetUrl, strings.NewReader(data.Encode()))
+			request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			require.NoError(t, err)
+
+			addCSRFToken(t, server, request)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLoginAPI(t *testing.T) {
+	user := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          loginRequest
+		buildStubs    func(repo *mockdb.MockRepo)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: loginRequest{
+				Username: user.Username,
+				Password: testPassword,
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+				require.Equal(t, "/index", recorder.Header().Get("Location"))
+				require.NotEmpty(t, recorder.Result().Cookies())
+			},
+		},
+		{
+			name: "Wrong Password",
+			body: loginRequest{
+				Username: user.Username,
+				Password: "wrong-password",
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "User Not Found",
+			body: loginRequest{
+				Username: user.Username,
+				Password: testPassword,
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Internal Error",
+			body: loginRequest{
+				Username: user.Username,
+				Password: testPassword,
+			},
+			buildStubs: func(repo *mockdb.MockRepo) {
+				repo.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			tc.buildStubs(repo)
+
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			data := url.Values{}
+			data.Set("username", tc.body.Username)
+			data.Set("password", tc.body.Password)
+
+			targetUrl := "/login"
+			request, err := http.NewRequest(http.MethodPost, targetUrl, strings.NewReader(data.Encode()))
+			request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			require.NoError(t, err)
+
+			addCSRFToken(t, server, request)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLogoutAPI(t *testing.T) {
+	user := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockdb.NewMockRepo(ctrl)
+	server := newTestServer(repo)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPost, "/logout", nil)
+	require.NoError(t, err)
+
+	addAuthSession(t, server, request, user)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusFound, recorder.Code)
+	require.Equal(t, "/login", recorder.Header().Get("Location"))
+
+	// The cleared session cookie must no longer grant access.
+	cookies := recorder.Result().Cookies()
+	require.NotEmpty(t, cookies)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/new", nil)
+	require.NoError(t, err)
+	for _, cookie := range cookies {
+		request.AddCookie(cookie)
+	}
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusFound, recorder.Code)
+	require.Equal(t, "/login", recorder.Header().Get("Location"))
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	testCases := []struct {
+		name          string
+		method        string
+		targetUrl     string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "HTML redirects to login",
+			method:    http.MethodGet,
+			targetUrl: "/index",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+				require.Equal(t, "/login", recorder.Header().Get("Location"))
+			},
+		},
+		{
+			name:      "JSON returns unauthorized",
+			method:    http.MethodGet,
+			targetUrl: "/api/v1/todos",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorCode(t, recorder.Body, "unauthorized")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockRepo(ctrl)
+			server := newTestServer(repo)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.targetUrl, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomUser(t *testing.T) db.User {
+	hashedPassword, err := util.HashPassword(testPassword)
+	require.NoError(t, err)
+
+	return db.User{
+		ID:             util.RandomInt(1, 1000),
+		Username:       util.RandomUsername(),
+		HashedPassword: hashedPassword,
+	}
+}
+
+// addAuthSession attaches a session cookie for user to request, signed with
+// the same store the server under test uses.
+// addAuthSession logs the user in on the request, with the CSRF token of the
+// session in the X-CSRF-TOKEN header.
+func addAuthSession(t *testing.T, server *Server, request *http.Request, user db.User) {
+	addTestSession(t, server, request, func(ctx *gin.Context) {
+		require.NoError(t, setAuthSession(ctx, user))
+	})
+}
+
+// addCSRFToken gives an anonymous request a session with a CSRF token, like
+// the login and signup forms get.
+func addCSRFToken(t *testing.T, server *Server, request *http.Request) {
+	addTestSession(t, server, request, func(ctx *gin.Context) {})
+}
+
+func addTestSession(t *testing.T, server *Server, request *http.Request, setup func(ctx *gin.Context)) {
+	router := gin.New()
+	router.Use(sessions.Sessions(sessionName, server.sessionStore), csrf.Middleware(csrf.Options{Secret: testSecrets.CSRFSecret}))
+	router.GET("/", func(ctx *gin.Context) {
+		setup(ctx)
+		request.Header.Set("X-CSRF-TOKEN", csrf.GetToken(ctx))
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// The session is saved once by setup and once more with the CSRF salt,
+	// and only the last cookie holds both.
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range recorder.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	for _, cookie := range cookies {
+		request.AddCookie(cookie)
+	}
+}
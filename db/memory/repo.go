@@ -0,0 +1,217 @@
+// Package memdb keeps the todos in memory behind the db.Repo interface, for
+// handler tests and demos that run without a database. Everything is lost
+// when the process stops.
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"first-app/todo_go/db/migration"
+	db "first-app/todo_go/db/sqlc"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Repo is a db.Repo that behaves like the MySQL one: IDs auto-increment from
+// 1, times are kept to the second and the lists come in the same order. Like
+// the tables, it does not check that the ids the rows refer to exist. It is
+// safe for concurrent use, and each method runs as one transaction.
+type Repo struct {
+	mu  sync.Mutex
+	now func() time.Time
+
+	schemaVersion int64
+	lastIDs       map[string]int64
+	users         map[int64]db.User
+	todos         map[int64]db.Todo
+	tags          map[int64]db.Tag
+	todoTags      map[int64]map[int64]bool
+	accessTokens  map[int64]db.AccessToken
+}
+
+var _ db.Repo = (*Repo)(nil)
+
+// Option configures the Repo built by New.
+type Option func(*Repo)
+
+// WithClock sets the function the repo reads the current time from, for the
+// create, update and delete dates.
+func WithClock(now func() time.Time) Option {
+	return func(repo *Repo) {
+		repo.now = now
+	}
+}
+
+// New returns an empty repo. Its schema version is the latest of the MySQL
+// migrations, the ones readyz expects by default.
+func New(opts ...Option) *Repo {
+	repo := &Repo{
+		now:          time.Now,
+		lastIDs:      map[string]int64{},
+		users:        map[int64]db.User{},
+		todos:        map[int64]db.Todo{},
+		tags:         map[int64]db.Tag{},
+		todoTags:     map[int64]map[int64]bool{},
+		accessTokens: map[int64]db.AccessToken{},
+	}
+	for _, opt := range opts {
+		opt(repo)
+	}
+
+	if migrations, err := migration.ForDriver("mysql"); err == nil {
+		repo.schemaVersion, _ = db.LatestMigrationVersion(migrations)
+	}
+	return repo
+}
+
+// result is the sql.Result of an insert or an update.
+type result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r result) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+// nextID returns the next auto-increment id of table.
+func (repo *Repo) nextID(table string) int64 {
+	repo.lastIDs[table]++
+	return repo.lastIDs[table]
+}
+
+// timestamp is the current time with the precision of a DATETIME column.
+func (repo *Repo) timestamp() sql.NullTime {
+	return sql.NullTime{Time: repo.now().Truncate(time.Second), Valid: true}
+}
+
+func duplicate(key string) error {
+	return fmt.Errorf("%w for key %s", db.ErrDuplicate, key)
+}
+
+func (repo *Repo) CreateUser(ctx context.Context, arg db.CreateUserParams) (sql.Result, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, user := range repo.users {
+		if user.Username == arg.Username {
+			return nil, duplicate("uq_users_username")
+		}
+	}
+	user := db.User{
+		ID:             repo.nextID("users"),
+		Username:       arg.Username,
+		HashedPassword: arg.HashedPassword,
+		CreateDate:     repo.timestamp(),
+	}
+	repo.users[user.ID] = user
+	return result{lastInsertID: user.ID, rowsAffected: 1}, nil
+}
+
+func (repo *Repo) GetUser(ctx context.Context, id int64) (db.User, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, ok := repo.users[id]
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (repo *Repo) GetUserByUsername(ctx context.Context, username string) (db.User, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, user := range repo.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return db.User{}, sql.ErrNoRows
+}
+
+func (repo *Repo) CreateAccessToken(ctx context.Context, arg db.CreateAccessTokenParams) (sql.Result, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, token := range repo.accessTokens {
+		if token.TokenHash == arg.TokenHash {
+			return nil, duplicate("uq_access_tokens_token_hash")
+		}
+	}
+	token := db.AccessToken{
+		ID:         repo.nextID("access_tokens"),
+		UserID:     arg.UserID,
+		Name:       arg.Name,
+		TokenHash:  arg.TokenHash,
+		Scopes:     arg.Scopes,
+		ExpiresAt:  arg.ExpiresAt,
+		CreateDate: repo.timestamp(),
+	}
+	repo.accessTokens[token.ID] = token
+	return result{lastInsertID: token.ID, rowsAffected: 1}, nil
+}
+
+func (repo *Repo) GetAccessTokenByHash(ctx context.Context, tokenHash string) (db.AccessToken, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, token := range repo.accessTokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return db.AccessToken{}, sql.ErrNoRows
+}
+
+func (repo *Repo) ListAccessTokens(ctx context.Context, userID int64) ([]db.AccessToken, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var items []db.AccessToken
+	for _, token := range repo.accessTokens {
+		if token.UserID == userID {
+			items = append(items, token)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID > items[j].ID })
+	return items, nil
+}
+
+func (repo *Repo) RevokeAccessToken(ctx context.Context, arg db.RevokeAccessTokenParams) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	token, ok := repo.accessTokens[arg.ID]
+	if ok && token.UserID == arg.UserID && !token.RevokedAt.Valid {
+		token.RevokedAt = repo.timestamp()
+		repo.accessTokens[token.ID] = token
+	}
+	return nil
+}
+
+func (repo *Repo) TouchAccessToken(ctx context.Context, id int64) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if token, ok := repo.accessTokens[id]; ok {
+		token.LastUsedAt = repo.timestamp()
+		repo.accessTokens[token.ID] = token
+	}
+	return nil
+}
+
+func (repo *Repo) GetSchemaVersion(ctx context.Context) (db.SchemaVersion, error) {
+	return db.SchemaVersion{Version: repo.schemaVersion}, nil
+}
+
+func (repo *Repo) Ping(ctx context.Context) error {
+	return nil
+}
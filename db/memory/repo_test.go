@@ -0,0 +1,47 @@
+package memdb
+
+import (
+	"context"
+	"first-app/todo_go/db/repotest"
+	db "first-app/todo_go/db/sqlc"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, New())
+}
+
+func TestConcurrentCreate(t *testing.T) {
+	repo := New()
+	n := 50
+
+	var wg sync.WaitGroup
+	ids := make([]int64, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := repo.CreateTodo(context.Background(), db.CreateTodoParams{UserID: 1})
+			require.NoError(t, err)
+			ids[i], err = result.LastInsertId()
+			require.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	require.ElementsMatch(t, seq(1, int64(n)), ids)
+	count, err := repo.CountTodo(context.Background(), db.TodoFilter{UserID: 1})
+	require.NoError(t, err)
+	require.Equal(t, int64(n), count)
+}
+
+func seq(from, to int64) []int64 {
+	var ids []int64
+	for id := from; id <= to; id++ {
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,184 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	db "first-app/todo_go/db/sqlc"
+	"sort"
+)
+
+// tagByName returns the tag of the user with the name.
+func (repo *Repo) tagByName(userID int64, name string) (db.Tag, bool) {
+	for _, tag := range repo.tags {
+		if tag.UserID == userID && tag.Name == name {
+			return tag, true
+		}
+	}
+	return db.Tag{}, false
+}
+
+func (repo *Repo) createTag(userID int64, name string) db.Tag {
+	tag := db.Tag{
+		ID:         repo.nextID("tags"),
+		UserID:     userID,
+		Name:       name,
+		CreateDate: repo.timestamp(),
+	}
+	repo.tags[tag.ID] = tag
+	return tag
+}
+
+func (repo *Repo) attachTag(todoID, tagID int64) {
+	if repo.todoTags[todoID] == nil {
+		repo.todoTags[todoID] = map[int64]bool{}
+	}
+	repo.todoTags[todoID][tagID] = true
+}
+
+// attachTagsByName attaches the named tags of the user to the todo, creating
+// the tags that do not exist yet.
+func (repo *Repo) attachTagsByName(todoID, userID int64, names []string) {
+	for _, name := range names {
+		tag, ok := repo.tagByName(userID, name)
+		if !ok {
+			tag = repo.createTag(userID, name)
+		}
+		repo.attachTag(todoID, tag.ID)
+	}
+}
+
+// tagsOfTodo returns the tags of the user attached to the todo, by name.
+func (repo *Repo) tagsOfTodo(todoID, userID int64) []db.Tag {
+	var items []db.Tag
+	for tagID := range repo.todoTags[todoID] {
+		if tag, ok := repo.tags[tagID]; ok && tag.UserID == userID {
+			items = append(items, tag)
+		}
+	}
+	sortTags(items)
+	return items
+}
+
+func sortTags(items []db.Tag) {
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+}
+
+func (repo *Repo) CreateTag(ctx context.Context, arg db.CreateTagParams) (sql.Result, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.tagByName(arg.UserID, arg.Name); ok {
+		return nil, duplicate("uq_tags_user_id_name")
+	}
+	tag := repo.createTag(arg.UserID, arg.Name)
+	return result{lastInsertID: tag.ID, rowsAffected: 1}, nil
+}
+
+// UpsertTag returns the id of the existing tag in LastInsertId, like the
+// LAST_INSERT_ID(id) trick of the MySQL query.
+func (repo *Repo) UpsertTag(ctx context.Context, arg db.UpsertTagParams) (sql.Result, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if tag, ok := repo.tagByName(arg.UserID, arg.Name); ok {
+		return result{lastInsertID: tag.ID}, nil
+	}
+	tag := repo.createTag(arg.UserID, arg.Name)
+	return result{lastInsertID: tag.ID, rowsAffected: 1}, nil
+}
+
+func (repo *Repo) GetTagByName(ctx context.Context, arg db.GetTagByNameParams) (db.Tag, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	tag, ok := repo.tagByName(arg.UserID, arg.Name)
+	if !ok {
+		return db.Tag{}, sql.ErrNoRows
+	}
+	return tag, nil
+}
+
+func (repo *Repo) ListTags(ctx context.Context, userID int64) ([]db.Tag, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var items []db.Tag
+	for _, tag := range repo.tags {
+		if tag.UserID == userID {
+			items = append(items, tag)
+		}
+	}
+	sortTags(items)
+	return items, nil
+}
+
+func (repo *Repo) ListTagsByTodo(ctx context.Context, arg db.ListTagsByTodoParams) ([]db.Tag, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.tagsOfTodo(arg.TodoID, arg.UserID), nil
+}
+
+func (repo *Repo) ListTagsByTodoIDs(ctx context.Context, arg db.ListTagsByTodoIDsParams) (map[int64][]db.Tag, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	items := map[int64][]db.Tag{}
+	for _, todoID := range arg.TodoIDs {
+		if tags := repo.tagsOfTodo(todoID, arg.UserID); len(tags) > 0 {
+			items[todoID] = tags
+		}
+	}
+	return items, nil
+}
+
+func (repo *Repo) AttachTag(ctx context.Context, arg db.AttachTagParams) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.attachTag(arg.TodoID, arg.TagID)
+	return nil
+}
+
+func (repo *Repo) DetachTag(ctx context.Context, arg db.DetachTagParams) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if tag, ok := repo.tags[arg.TagID]; ok && tag.UserID == arg.UserID {
+		delete(repo.todoTags[arg.TodoID], arg.TagID)
+	}
+	return nil
+}
+
+func (repo *Repo) DetachAllTags(ctx context.Context, todoID int64) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	delete(repo.todoTags, todoID)
+	return nil
+}
+
+func (repo *Repo) CreateTodoTx(ctx context.Context, arg db.CreateTodoTxParams) (db.CreateTodoTxResult, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	todo := repo.createTodo(arg.CreateTodoParams)
+	repo.attachTagsByName(todo.ID, arg.UserID, arg.TagNames)
+	return db.CreateTodoTxResult{
+		Todo: todo,
+		Tags: repo.tagsOfTodo(todo.ID, arg.UserID),
+	}, nil
+}
+
+func (repo *Repo) ReplaceTodoTagsTx(ctx context.Context, arg db.ReplaceTodoTagsTxParams) ([]db.Tag, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	todo, ok := repo.getTodo(arg.TodoID, arg.UserID, false)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	delete(repo.todoTags, todo.ID)
+	repo.attachTagsByName(todo.ID, arg.UserID, arg.TagNames)
+	return repo.tagsOfTodo(todo.ID, arg.UserID), nil
+}
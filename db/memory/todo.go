@@ -0,0 +1,417 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	db "first-app/todo_go/db/sqlc"
+	"sort"
+	"strings"
+	"time"
+)
+
+// getTodo returns the todo of the user, trashed or not as the caller asks.
+func (repo *Repo) getTodo(id, userID int64, trashed bool) (db.Todo, bool) {
+	todo, ok := repo.todos[id]
+	if !ok || todo.UserID != userID || todo.DeletedAt.Valid != trashed {
+		return db.Todo{}, false
+	}
+	return todo, true
+}
+
+// saveTodo stores a changed todo. Like the ON UPDATE clause of update_date,
+// the update date only moves when a column actually changed.
+func (repo *Repo) saveTodo(todo db.Todo) {
+	if todo != repo.todos[todo.ID] {
+		todo.UpdateDate = repo.timestamp()
+	}
+	repo.todos[todo.ID] = todo
+}
+
+// trashTodo moves a todo to the trash or back, at deletedAt. Like the trash
+// queries, it leaves the update date alone.
+func (repo *Repo) trashTodo(todo db.Todo, deletedAt sql.NullTime) {
+	todo.DeletedAt = deletedAt
+	repo.todos[todo.ID] = todo
+}
+
+// removeTodo deletes the todo along with its tag links.
+func (repo *Repo) removeTodo(id int64) {
+	delete(repo.todos, id)
+	delete(repo.todoTags, id)
+}
+
+func (repo *Repo) createTodo(arg db.CreateTodoParams) db.Todo {
+	todo := db.Todo{
+		ID:          repo.nextID("Todo"),
+		Title:       arg.Title,
+		Description: arg.Description,
+		CreateDate:  repo.timestamp(),
+		UserID:      arg.UserID,
+		Status:      db.TodoStatusOpen,
+		Priority:    arg.Priority,
+		DueAt:       arg.DueAt,
+	}
+	if len(todo.Priority) == 0 {
+		todo.Priority = db.TodoPriorityMedium
+	}
+	repo.todos[todo.ID] = todo
+	return todo
+}
+
+func (repo *Repo) CreateTodo(ctx context.Context, arg db.CreateTodoParams) (sql.Result, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	todo := repo.createTodo(arg)
+	return result{lastInsertID: todo.ID, rowsAffected: 1}, nil
+}
+
+func (repo *Repo) GetTodo(ctx context.Context, arg db.GetTodoParams) (db.Todo, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	todo, ok := repo.getTodo(arg.ID, arg.UserID, false)
+	if !ok {
+		return db.Todo{}, sql.ErrNoRows
+	}
+	return todo, nil
+}
+
+func (repo *Repo) UpdateTodo(ctx context.Context, arg db.UpdateTodoParams) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if todo, ok := repo.getTodo(arg.ID, arg.UserID, false); ok {
+		todo.Description = arg.Description
+		todo.Status = arg.Status
+		todo.Priority = arg.Priority
+		todo.DueAt = arg.DueAt
+		repo.saveTodo(todo)
+	}
+	return nil
+}
+
+func (repo *Repo) ToggleTodo(ctx context.Context, arg db.ToggleTodoParams) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if todo, ok := repo.getTodo(arg.ID, arg.UserID, false); ok {
+		if todo.Status == db.TodoStatusDone {
+			todo.Status = db.TodoStatusOpen
+		} else {
+			todo.Status = db.TodoStatusDone
+		}
+		repo.saveTodo(todo)
+	}
+	return nil
+}
+
+func (repo *Repo) DeleteTodo(ctx context.Context, arg db.DeleteTodoParams) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if todo, ok := repo.getTodo(arg.ID, arg.UserID, false); ok {
+		repo.trashTodo(todo, repo.timestamp())
+	}
+	return nil
+}
+
+func (repo *Repo) DeleteTodoListTx(ctx context.Context, arg db.DeleteTodoListParams) (db.DeleteTodoListTxResult, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	result := db.DeleteTodoListTxResult{NotFound: []int64{}}
+	deleted := map[int64]bool{}
+	for _, id := range arg.IDs {
+		todo, ok := repo.getTodo(id, arg.UserID, false)
+		if !ok {
+			if !deleted[id] {
+				result.NotFound = append(result.NotFound, id)
+			}
+			continue
+		}
+		repo.trashTodo(todo, repo.timestamp())
+		deleted[id] = true
+		result.Deleted++
+	}
+	return result, nil
+}
+
+func (repo *Repo) RestoreTodo(ctx context.Context, arg db.RestoreTodoParams) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	todo, ok := repo.getTodo(arg.ID, arg.UserID, true)
+	if !ok {
+		return 0, nil
+	}
+	repo.trashTodo(todo, sql.NullTime{})
+	return 1, nil
+}
+
+func (repo *Repo) PurgeTodo(ctx context.Context, arg db.PurgeTodoParams) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.getTodo(arg.ID, arg.UserID, true); !ok {
+		return 0, nil
+	}
+	repo.removeTodo(arg.ID)
+	return 1, nil
+}
+
+func (repo *Repo) PurgeDeletedTodo(ctx context.Context, retentionSeconds int64) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	cutoff := repo.now().Add(-time.Duration(retentionSeconds) * time.Second)
+	var purged int64
+	for id, todo := range repo.todos {
+		if todo.DeletedAt.Valid && todo.DeletedAt.Time.Before(cutoff) {
+			repo.removeTodo(id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (repo *Repo) ClearTodo(ctx context.Context, userID int64) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for id, todo := range repo.todos {
+		if todo.UserID == userID {
+			repo.removeTodo(id)
+		}
+	}
+	return nil
+}
+
+func (repo *Repo) CountDeletedTodo(ctx context.Context, userID int64) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var count int64
+	for _, todo := range repo.todos {
+		if todo.UserID == userID && todo.DeletedAt.Valid {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (repo *Repo) ListDeletedTodo(ctx context.Context, arg db.ListDeletedTodoParams) ([]db.Todo, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var items []db.Todo
+	for _, todo := range repo.todos {
+		if todo.UserID == arg.UserID && todo.DeletedAt.Valid {
+			items = append(items, todo)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].DeletedAt.Time.Equal(items[j].DeletedAt.Time) {
+			return items[i].DeletedAt.Time.After(items[j].DeletedAt.Time)
+		}
+		return items[i].ID > items[j].ID
+	})
+	return page(items, arg.Offset, arg.Limit), nil
+}
+
+func (repo *Repo) CountTodo(ctx context.Context, arg db.TodoFilter) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return int64(len(repo.filterTodo(arg))), nil
+}
+
+func (repo *Repo) ListTodo(ctx context.Context, arg db.ListTodoParams) ([]db.Todo, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	items := repo.filterTodo(arg.TodoFilter)
+	if arg.Cursor == nil {
+		sortTodo(items, arg.SortBy, arg.SortOrder)
+		return page(items, arg.Offset, arg.Limit), nil
+	}
+
+	// Read the page from the cursor on like the keyset query does, in
+	// reverse for the page before it.
+	cursor := *arg.Cursor
+	order := cursor.SortOrder
+	if cursor.Before {
+		order = reverseOrder(order)
+	}
+	mark := cursorTodo(cursor)
+	var after []db.Todo
+	for _, todo := range items {
+		if compareTodo(todo, mark, cursor.SortBy, order) > 0 {
+			after = append(after, todo)
+		}
+	}
+	sortTodo(after, cursor.SortBy, order)
+	after = page(after, 0, arg.Limit)
+	if cursor.Before {
+		for i, j := 0, len(after)-1; i < j; i, j = i+1, j-1 {
+			after[i], after[j] = after[j], after[i]
+		}
+	}
+	return after, nil
+}
+
+// cursorTodo returns a todo with the sort key of the cursor, to compare the
+// todos of the list with.
+func cursorTodo(cursor db.TodoCursor) db.Todo {
+	todo := db.Todo{ID: cursor.ID, Title: cursor.Title}
+	switch cursor.SortBy {
+	case db.TodoSortCreateDate:
+		todo.CreateDate = cursor.Date
+	case db.TodoSortUpdateDate:
+		todo.UpdateDate = cursor.Date
+	}
+	return todo
+}
+
+func reverseOrder(order db.SortOrder) db.SortOrder {
+	if order == db.SortDesc {
+		return db.SortAsc
+	}
+	return db.SortDesc
+}
+
+// filterTodo returns the todos that pass the filter, in no particular order.
+func (repo *Repo) filterTodo(arg db.TodoFilter) []db.Todo {
+	var items []db.Todo
+	for _, todo := range repo.todos {
+		if todo.UserID == arg.UserID && !todo.DeletedAt.Valid && repo.matchTodo(todo, arg) {
+			items = append(items, todo)
+		}
+	}
+	return items
+}
+
+func (repo *Repo) matchTodo(todo db.Todo, arg db.TodoFilter) bool {
+	if len(arg.Query) > 0 && !matchQuery(todo, arg.Query) {
+		return false
+	}
+	if len(arg.Tag) > 0 && !repo.hasTag(todo, arg.Tag) {
+		return false
+	}
+	if arg.CreatedFrom.Valid && todo.CreateDate.Time.Before(arg.CreatedFrom.Time) {
+		return false
+	}
+	if arg.CreatedTo.Valid && !todo.CreateDate.Time.Before(arg.CreatedTo.Time) {
+		return false
+	}
+	if arg.DueFrom.Valid && (!todo.DueAt.Valid || todo.DueAt.Time.Before(arg.DueFrom.Time)) {
+		return false
+	}
+	if arg.DueTo.Valid && (!todo.DueAt.Valid || !todo.DueAt.Time.Before(arg.DueTo.Time)) {
+		return false
+	}
+	return true
+}
+
+// matchQuery stands in for the FULLTEXT search: a todo matches when any word
+// of the query appears in its title or description, ignoring case.
+func matchQuery(todo db.Todo, query string) bool {
+	text := strings.ToLower(todo.Title.String + " " + todo.Description.String)
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if strings.Contains(text, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func (repo *Repo) hasTag(todo db.Todo, name string) bool {
+	for tagID := range repo.todoTags[todo.ID] {
+		if tag, ok := repo.tags[tagID]; ok && tag.UserID == todo.UserID && tag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sortTodo orders the todos like the ORDER BY of ListTodo: unknown fields and
+// orders fall back to id ascending, ties are broken by id and NULLs come
+// first in ascending order.
+func sortTodo(items []db.Todo, sortBy db.TodoSortField, order db.SortOrder) {
+	sort.Slice(items, func(i, j int) bool {
+		return compareTodo(items[i], items[j], sortBy, order) < 0
+	})
+}
+
+// compareTodo returns whether a comes before (-1) or after (1) b in the
+// order of the list.
+func compareTodo(a, b db.Todo, sortBy db.TodoSortField, order db.SortOrder) int {
+	c := 0
+	switch sortBy {
+	case db.TodoSortCreateDate:
+		c = compareTime(a.CreateDate, b.CreateDate)
+	case db.TodoSortUpdateDate:
+		c = compareTime(a.UpdateDate, b.UpdateDate)
+	case db.TodoSortTitle:
+		c = compareString(a.Title, b.Title)
+	}
+	if c == 0 {
+		c = compareID(a.ID, b.ID)
+	}
+	if order == db.SortDesc {
+		return -c
+	}
+	return c
+}
+
+func compareID(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareTime(a, b sql.NullTime) int {
+	switch {
+	case !a.Valid || !b.Valid:
+		return compareNull(a.Valid, b.Valid)
+	case a.Time.Before(b.Time):
+		return -1
+	case a.Time.After(b.Time):
+		return 1
+	}
+	return 0
+}
+
+// compareString compares like the case-insensitive collation of the table.
+func compareString(a, b sql.NullString) int {
+	if !a.Valid || !b.Valid {
+		return compareNull(a.Valid, b.Valid)
+	}
+	return strings.Compare(strings.ToLower(a.String), strings.ToLower(b.String))
+}
+
+func compareNull(aValid, bValid bool) int {
+	switch {
+	case aValid == bValid:
+		return 0
+	case !aValid:
+		return -1
+	}
+	return 1
+}
+
+// page cuts the page out of the sorted todos like LIMIT offset, limit.
+func page(items []db.Todo, offset, limit int32) []db.Todo {
+	if offset < 0 || limit <= 0 || int(offset) >= len(items) {
+		return nil
+	}
+	end := int(offset) + int(limit)
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
+}
@@ -0,0 +1,24 @@
+// Package migration embeds the SQL migrations into the binary.
+package migration
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+// files holds one directory of migrations per DB_DRIVER. Every directory has
+// the same versions, named like 000001_init_schema.up.sql with a matching
+// .down.sql, so a version means the same schema whatever the database.
+//
+//go:embed mysql postgres sqlite
+var files embed.FS
+
+// ForDriver returns the migrations for the DB_DRIVER driver.
+func ForDriver(driver string) (fs.FS, error) {
+	switch driver {
+	case "mysql", "postgres", "sqlite":
+		return fs.Sub(files, driver)
+	}
+	return nil, fmt.Errorf("no migrations for DB_DRIVER %q", driver)
+}
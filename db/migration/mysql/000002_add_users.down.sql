@@ -0,0 +1,4 @@
+ALTER TABLE `Todo` DROP FOREIGN KEY `fk_todo_user_id`;
+ALTER TABLE `Todo` DROP INDEX `idx_todo_user_id`;
+ALTER TABLE `Todo` DROP COLUMN `user_id`;
+DROP TABLE IF EXISTS `users`;
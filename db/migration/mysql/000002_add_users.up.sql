@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS `users` (
+  `id` BIGINT AUTO_INCREMENT PRIMARY KEY,
+  `username` varchar(64) NOT NULL,
+  `hashed_password` varchar(255) NOT NULL,
+  `create_date` datetime DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY `uq_users_username` (`username`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- The todos created before there were accounts go to a legacy user. Its
+-- password hash matches no password, so move the todos to a real account by
+-- hand, see "Upgrading from a database without accounts" in the README.
+INSERT INTO `users` (`username`, `hashed_password`)
+SELECT 'legacy', '!' FROM DUAL WHERE EXISTS (SELECT 1 FROM `Todo`);
+
+ALTER TABLE `Todo` ADD COLUMN `user_id` BIGINT DEFAULT NULL;
+-- Setting update_date to itself keeps ON UPDATE from touching it.
+UPDATE `Todo` SET
+  `user_id` = (SELECT `id` FROM `users` WHERE `username` = 'legacy'),
+  `update_date` = `update_date`;
+ALTER TABLE `Todo` MODIFY `user_id` BIGINT NOT NULL;
+ALTER TABLE `Todo` ADD INDEX `idx_todo_user_id` (`user_id`);
+ALTER TABLE `Todo` ADD CONSTRAINT `fk_todo_user_id` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;
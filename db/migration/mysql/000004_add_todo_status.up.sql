@@ -0,0 +1,4 @@
+ALTER TABLE `Todo` ADD COLUMN `status` ENUM('open', 'in_progress', 'done') NOT NULL DEFAULT 'open';
+ALTER TABLE `Todo` ADD COLUMN `priority` ENUM('low', 'medium', 'high') NOT NULL DEFAULT 'medium';
+ALTER TABLE `Todo` ADD COLUMN `due_at` datetime DEFAULT NULL;
+ALTER TABLE `Todo` ADD INDEX `idx_todo_user_id_status` (`user_id`, `status`);
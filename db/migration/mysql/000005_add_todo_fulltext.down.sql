@@ -0,0 +1 @@
+ALTER TABLE `Todo` DROP INDEX `ft_todo_title_description`;
\ No newline at end of file
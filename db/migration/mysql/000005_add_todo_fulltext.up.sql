@@ -0,0 +1 @@
+ALTER TABLE `Todo` ADD FULLTEXT INDEX `ft_todo_title_description` (`title`, `description`);
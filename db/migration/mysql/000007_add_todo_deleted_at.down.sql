@@ -0,0 +1,3 @@
+ALTER TABLE `Todo` DROP INDEX `idx_todo_deleted_at`;
+ALTER TABLE `Todo` DROP INDEX `idx_todo_user_id_deleted_at`;
+ALTER TABLE `Todo` DROP COLUMN `deleted_at`;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE `Todo` ADD COLUMN `deleted_at` datetime DEFAULT NULL;
+ALTER TABLE `Todo` ADD INDEX `idx_todo_user_id_deleted_at` (`user_id`, `deleted_at`);
+ALTER TABLE `Todo` ADD INDEX `idx_todo_deleted_at` (`deleted_at`);
\ No newline at end of file
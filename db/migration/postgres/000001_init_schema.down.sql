@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS Todo;
+DROP FUNCTION IF EXISTS set_update_date;
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS Todo (
+  id BIGSERIAL PRIMARY KEY,
+  title varchar(255) DEFAULT NULL,
+  description text,
+  create_date timestamptz DEFAULT CURRENT_TIMESTAMP,
+  update_date timestamptz DEFAULT NULL
+);
+
+-- Stands in for the ON UPDATE CURRENT_TIMESTAMP of the MySQL schema.
+CREATE OR REPLACE FUNCTION set_update_date() RETURNS trigger AS $$
+BEGIN
+  NEW.update_date = CURRENT_TIMESTAMP;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER todo_set_update_date BEFORE UPDATE ON Todo
+FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*)
+EXECUTE FUNCTION set_update_date();
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_todo_user_id;
+ALTER TABLE Todo DROP COLUMN user_id;
+DROP TABLE IF EXISTS users;
\ No newline at end of file
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS users (
+  id BIGSERIAL PRIMARY KEY,
+  username varchar(64) NOT NULL,
+  hashed_password varchar(255) NOT NULL,
+  create_date timestamptz DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uq_users_username UNIQUE (username)
+);
+
+-- The todos created before there were accounts go to a legacy user. Its
+-- password hash matches no password, so move the todos to a real account by
+-- hand, see "Upgrading from a database without accounts" in the README.
+INSERT INTO users (username, hashed_password)
+SELECT 'legacy', '!' WHERE EXISTS (SELECT 1 FROM Todo);
+
+ALTER TABLE Todo ADD COLUMN user_id BIGINT;
+ALTER TABLE Todo DISABLE TRIGGER todo_set_update_date;
+UPDATE Todo SET user_id = (SELECT id FROM users WHERE username = 'legacy');
+ALTER TABLE Todo ENABLE TRIGGER todo_set_update_date;
+ALTER TABLE Todo ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE Todo ADD CONSTRAINT fk_todo_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+CREATE INDEX idx_todo_user_id ON Todo (user_id);
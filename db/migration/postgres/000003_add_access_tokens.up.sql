@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS access_tokens (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL,
+  name varchar(255) NOT NULL,
+  token_hash char(64) NOT NULL,
+  scopes varchar(255) NOT NULL,
+  expires_at timestamptz DEFAULT NULL,
+  last_used_at timestamptz DEFAULT NULL,
+  revoked_at timestamptz DEFAULT NULL,
+  create_date timestamptz DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uq_access_tokens_token_hash UNIQUE (token_hash),
+  CONSTRAINT fk_access_tokens_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_access_tokens_user_id ON access_tokens (user_id);
\ No newline at end of file
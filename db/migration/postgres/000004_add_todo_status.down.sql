@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_todo_user_id_status;
+ALTER TABLE Todo DROP COLUMN due_at;
+ALTER TABLE Todo DROP COLUMN priority;
+ALTER TABLE Todo DROP COLUMN status;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS ft_todo_title_description;
\ No newline at end of file
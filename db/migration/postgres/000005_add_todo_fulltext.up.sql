@@ -0,0 +1,4 @@
+-- The search condition in db/sqlc/dialect.go has to use the same expression
+-- for the index to be picked.
+CREATE INDEX ft_todo_title_description ON Todo
+USING GIN (to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(description, '')));
\ No newline at end of file
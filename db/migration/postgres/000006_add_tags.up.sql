@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS tags (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL,
+  name varchar(64) NOT NULL,
+  create_date timestamptz DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uq_tags_user_id_name UNIQUE (user_id, name),
+  CONSTRAINT fk_tags_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS todo_tags (
+  todo_id BIGINT NOT NULL,
+  tag_id BIGINT NOT NULL,
+  PRIMARY KEY (todo_id, tag_id),
+  CONSTRAINT fk_todo_tags_todo_id FOREIGN KEY (todo_id) REFERENCES Todo (id) ON DELETE CASCADE,
+  CONSTRAINT fk_todo_tags_tag_id FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_todo_tags_tag_id ON todo_tags (tag_id);
\ No newline at end of file
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS todo_set_update_date ON Todo;
+CREATE TRIGGER todo_set_update_date BEFORE UPDATE ON Todo
+FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*)
+EXECUTE FUNCTION set_update_date();
+
+DROP INDEX IF EXISTS idx_todo_deleted_at;
+DROP INDEX IF EXISTS idx_todo_user_id_deleted_at;
+ALTER TABLE Todo DROP COLUMN deleted_at;
\ No newline at end of file
@@ -0,0 +1,10 @@
+ALTER TABLE Todo ADD COLUMN deleted_at timestamptz DEFAULT NULL;
+CREATE INDEX idx_todo_user_id_deleted_at ON Todo (user_id, deleted_at);
+CREATE INDEX idx_todo_deleted_at ON Todo (deleted_at);
+
+-- Moving a todo to the trash or back leaves update_date alone, like the
+-- update_date = update_date of the trash queries does on MySQL.
+DROP TRIGGER IF EXISTS todo_set_update_date ON Todo;
+CREATE TRIGGER todo_set_update_date BEFORE UPDATE ON Todo
+FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.* AND OLD.deleted_at IS NOT DISTINCT FROM NEW.deleted_at)
+EXECUTE FUNCTION set_update_date();
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS Todo;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS Todo (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  title varchar(255) DEFAULT NULL,
+  description text,
+  create_date datetime DEFAULT CURRENT_TIMESTAMP,
+  update_date datetime DEFAULT NULL
+);
+
+-- Stands in for the ON UPDATE CURRENT_TIMESTAMP of the MySQL schema.
+CREATE TRIGGER IF NOT EXISTS todo_set_update_date AFTER UPDATE ON Todo
+FOR EACH ROW WHEN NEW.update_date IS OLD.update_date
+BEGIN
+  UPDATE Todo SET update_date = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- SQLite cannot drop a column with a foreign key, so the table is rebuilt.
+CREATE TABLE Todo_new (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  title varchar(255) DEFAULT NULL,
+  description text,
+  create_date datetime DEFAULT CURRENT_TIMESTAMP,
+  update_date datetime DEFAULT NULL
+);
+INSERT INTO Todo_new (id, title, description, create_date, update_date)
+SELECT id, title, description, create_date, update_date FROM Todo;
+DROP TABLE Todo;
+ALTER TABLE Todo_new RENAME TO Todo;
+
+CREATE TRIGGER IF NOT EXISTS todo_set_update_date AFTER UPDATE ON Todo
+FOR EACH ROW WHEN NEW.update_date IS OLD.update_date
+BEGIN
+  UPDATE Todo SET update_date = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS users (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  username varchar(64) NOT NULL,
+  hashed_password varchar(255) NOT NULL,
+  create_date datetime DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uq_users_username UNIQUE (username)
+);
+
+-- The todos created before there were accounts go to a legacy user. Its
+-- password hash matches no password, so move the todos to a real account by
+-- hand, see "Upgrading from a database without accounts" in the README.
+INSERT INTO users (username, hashed_password)
+SELECT 'legacy', '!' WHERE EXISTS (SELECT 1 FROM Todo);
+
+-- SQLite cannot add a NOT NULL column with a foreign key, so the table is
+-- rebuilt. Dropping the old table drops its trigger too.
+CREATE TABLE Todo_new (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  title varchar(255) DEFAULT NULL,
+  description text,
+  create_date datetime DEFAULT CURRENT_TIMESTAMP,
+  update_date datetime DEFAULT NULL,
+  user_id BIGINT NOT NULL,
+  CONSTRAINT fk_todo_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+INSERT INTO Todo_new (id, title, description, create_date, update_date, user_id)
+SELECT id, title, description, create_date, update_date, (SELECT id FROM users WHERE username = 'legacy')
+FROM Todo;
+DROP TABLE Todo;
+ALTER TABLE Todo_new RENAME TO Todo;
+
+CREATE TRIGGER IF NOT EXISTS todo_set_update_date AFTER UPDATE ON Todo
+FOR EACH ROW WHEN NEW.update_date IS OLD.update_date
+BEGIN
+  UPDATE Todo SET update_date = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+CREATE INDEX idx_todo_user_id ON Todo (user_id);
@@ -0,0 +1,6 @@
+ALTER TABLE Todo ADD COLUMN status varchar(16) NOT NULL DEFAULT 'open'
+  CONSTRAINT ck_todo_status CHECK (status IN ('open', 'in_progress', 'done'));
+ALTER TABLE Todo ADD COLUMN priority varchar(16) NOT NULL DEFAULT 'medium'
+  CONSTRAINT ck_todo_priority CHECK (priority IN ('low', 'medium', 'high'));
+ALTER TABLE Todo ADD COLUMN due_at datetime DEFAULT NULL;
+CREATE INDEX idx_todo_user_id_status ON Todo (user_id, status);
\ No newline at end of file
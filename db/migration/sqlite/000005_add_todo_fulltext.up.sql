@@ -0,0 +1,4 @@
+-- SQLite has no FULLTEXT index: the search condition in db/sqlc/dialect.go
+-- scans the title and description of the user's todos instead. The version
+-- is kept so that every driver has the same migration versions.
+SELECT 1;
\ No newline at end of file
@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS todo_set_update_date;
+CREATE TRIGGER todo_set_update_date AFTER UPDATE ON Todo
+FOR EACH ROW WHEN NEW.update_date IS OLD.update_date
+BEGIN
+  UPDATE Todo SET update_date = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+DROP INDEX IF EXISTS idx_todo_deleted_at;
+DROP INDEX IF EXISTS idx_todo_user_id_deleted_at;
+ALTER TABLE Todo DROP COLUMN deleted_at;
\ No newline at end of file
@@ -0,0 +1,12 @@
+ALTER TABLE Todo ADD COLUMN deleted_at datetime DEFAULT NULL;
+CREATE INDEX idx_todo_user_id_deleted_at ON Todo (user_id, deleted_at);
+CREATE INDEX idx_todo_deleted_at ON Todo (deleted_at);
+
+-- Moving a todo to the trash or back leaves update_date alone, like the
+-- update_date = update_date of the trash queries does on MySQL.
+DROP TRIGGER IF EXISTS todo_set_update_date;
+CREATE TRIGGER todo_set_update_date AFTER UPDATE ON Todo
+FOR EACH ROW WHEN NEW.update_date IS OLD.update_date AND NEW.deleted_at IS OLD.deleted_at
+BEGIN
+  UPDATE Todo SET update_date = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
\ No newline at end of file
@@ -36,33 +36,92 @@ func (m *MockRepo) EXPECT() *MockRepoMockRecorder {
 	return m.recorder
 }
 
+// AttachTag mocks base method.
+func (m *MockRepo) AttachTag(arg0 context.Context, arg1 db.AttachTagParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachTag", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AttachTag indicates an expected call of AttachTag.
+func (mr *MockRepoMockRecorder) AttachTag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachTag", reflect.TypeOf((*MockRepo)(nil).AttachTag), arg0, arg1)
+}
+
 // ClearTodo mocks base method.
-func (m *MockRepo) ClearTodo(arg0 context.Context) error {
+func (m *MockRepo) ClearTodo(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ClearTodo", arg0)
+	ret := m.ctrl.Call(m, "ClearTodo", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ClearTodo indicates an expected call of ClearTodo.
-func (mr *MockRepoMockRecorder) ClearTodo(arg0 interface{}) *gomock.Call {
+func (mr *MockRepoMockRecorder) ClearTodo(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearTodo", reflect.TypeOf((*MockRepo)(nil).ClearTodo), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearTodo", reflect.TypeOf((*MockRepo)(nil).ClearTodo), arg0, arg1)
+}
+
+// CountDeletedTodo mocks base method.
+func (m *MockRepo) CountDeletedTodo(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountDeletedTodo", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountDeletedTodo indicates an expected call of CountDeletedTodo.
+func (mr *MockRepoMockRecorder) CountDeletedTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDeletedTodo", reflect.TypeOf((*MockRepo)(nil).CountDeletedTodo), arg0, arg1)
 }
 
 // CountTodo mocks base method.
-func (m *MockRepo) CountTodo(arg0 context.Context) (int64, error) {
+func (m *MockRepo) CountTodo(arg0 context.Context, arg1 db.TodoFilter) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CountTodo", arg0)
+	ret := m.ctrl.Call(m, "CountTodo", arg0, arg1)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CountTodo indicates an expected call of CountTodo.
-func (mr *MockRepoMockRecorder) CountTodo(arg0 interface{}) *gomock.Call {
+func (mr *MockRepoMockRecorder) CountTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTodo", reflect.TypeOf((*MockRepo)(nil).CountTodo), arg0, arg1)
+}
+
+// CreateAccessToken mocks base method.
+func (m *MockRepo) CreateAccessToken(arg0 context.Context, arg1 db.CreateAccessTokenParams) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccessToken", arg0, arg1)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccessToken indicates an expected call of CreateAccessToken.
+func (mr *MockRepoMockRecorder) CreateAccessToken(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTodo", reflect.TypeOf((*MockRepo)(nil).CountTodo), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccessToken", reflect.TypeOf((*MockRepo)(nil).CreateAccessToken), arg0, arg1)
+}
+
+// CreateTag mocks base method.
+func (m *MockRepo) CreateTag(arg0 context.Context, arg1 db.CreateTagParams) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTag", arg0, arg1)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTag indicates an expected call of CreateTag.
+func (mr *MockRepoMockRecorder) CreateTag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockRepo)(nil).CreateTag), arg0, arg1)
 }
 
 // CreateTodo mocks base method.
@@ -80,8 +139,38 @@ func (mr *MockRepoMockRecorder) CreateTodo(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTodo", reflect.TypeOf((*MockRepo)(nil).CreateTodo), arg0, arg1)
 }
 
+// CreateTodoTx mocks base method.
+func (m *MockRepo) CreateTodoTx(arg0 context.Context, arg1 db.CreateTodoTxParams) (db.CreateTodoTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTodoTx", arg0, arg1)
+	ret0, _ := ret[0].(db.CreateTodoTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTodoTx indicates an expected call of CreateTodoTx.
+func (mr *MockRepoMockRecorder) CreateTodoTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTodoTx", reflect.TypeOf((*MockRepo)(nil).CreateTodoTx), arg0, arg1)
+}
+
+// CreateUser mocks base method.
+func (m *MockRepo) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockRepoMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockRepo)(nil).CreateUser), arg0, arg1)
+}
+
 // DeleteTodo mocks base method.
-func (m *MockRepo) DeleteTodo(arg0 context.Context, arg1 int64) error {
+func (m *MockRepo) DeleteTodo(arg0 context.Context, arg1 db.DeleteTodoParams) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteTodo", arg0, arg1)
 	ret0, _ := ret[0].(error)
@@ -94,22 +183,96 @@ func (mr *MockRepoMockRecorder) DeleteTodo(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTodo", reflect.TypeOf((*MockRepo)(nil).DeleteTodo), arg0, arg1)
 }
 
-// DeleteTodoList mocks base method.
-func (m *MockRepo) DeleteTodoList(arg0 context.Context, arg1 string) error {
+// DeleteTodoListTx mocks base method.
+func (m *MockRepo) DeleteTodoListTx(arg0 context.Context, arg1 db.DeleteTodoListParams) (db.DeleteTodoListTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteTodoList", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteTodoListTx", arg0, arg1)
+	ret0, _ := ret[0].(db.DeleteTodoListTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteTodoListTx indicates an expected call of DeleteTodoListTx.
+func (mr *MockRepoMockRecorder) DeleteTodoListTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTodoListTx", reflect.TypeOf((*MockRepo)(nil).DeleteTodoListTx), arg0, arg1)
+}
+
+// DetachAllTags mocks base method.
+func (m *MockRepo) DetachAllTags(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetachAllTags", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DetachAllTags indicates an expected call of DetachAllTags.
+func (mr *MockRepoMockRecorder) DetachAllTags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachAllTags", reflect.TypeOf((*MockRepo)(nil).DetachAllTags), arg0, arg1)
+}
+
+// DetachTag mocks base method.
+func (m *MockRepo) DetachTag(arg0 context.Context, arg1 db.DetachTagParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetachTag", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteTodoList indicates an expected call of DeleteTodoList.
-func (mr *MockRepoMockRecorder) DeleteTodoList(arg0, arg1 interface{}) *gomock.Call {
+// DetachTag indicates an expected call of DetachTag.
+func (mr *MockRepoMockRecorder) DetachTag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachTag", reflect.TypeOf((*MockRepo)(nil).DetachTag), arg0, arg1)
+}
+
+// GetAccessTokenByHash mocks base method.
+func (m *MockRepo) GetAccessTokenByHash(arg0 context.Context, arg1 string) (db.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessTokenByHash", arg0, arg1)
+	ret0, _ := ret[0].(db.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessTokenByHash indicates an expected call of GetAccessTokenByHash.
+func (mr *MockRepoMockRecorder) GetAccessTokenByHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessTokenByHash", reflect.TypeOf((*MockRepo)(nil).GetAccessTokenByHash), arg0, arg1)
+}
+
+// GetSchemaVersion mocks base method.
+func (m *MockRepo) GetSchemaVersion(arg0 context.Context) (db.SchemaVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchemaVersion", arg0)
+	ret0, _ := ret[0].(db.SchemaVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchemaVersion indicates an expected call of GetSchemaVersion.
+func (mr *MockRepoMockRecorder) GetSchemaVersion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchemaVersion", reflect.TypeOf((*MockRepo)(nil).GetSchemaVersion), arg0)
+}
+
+// GetTagByName mocks base method.
+func (m *MockRepo) GetTagByName(arg0 context.Context, arg1 db.GetTagByNameParams) (db.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTagByName", arg0, arg1)
+	ret0, _ := ret[0].(db.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTagByName indicates an expected call of GetTagByName.
+func (mr *MockRepoMockRecorder) GetTagByName(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTodoList", reflect.TypeOf((*MockRepo)(nil).DeleteTodoList), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTagByName", reflect.TypeOf((*MockRepo)(nil).GetTagByName), arg0, arg1)
 }
 
 // GetTodo mocks base method.
-func (m *MockRepo) GetTodo(arg0 context.Context, arg1 int64) (db.Todo, error) {
+func (m *MockRepo) GetTodo(arg0 context.Context, arg1 db.GetTodoParams) (db.Todo, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetTodo", arg0, arg1)
 	ret0, _ := ret[0].(db.Todo)
@@ -123,6 +286,111 @@ func (mr *MockRepoMockRecorder) GetTodo(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTodo", reflect.TypeOf((*MockRepo)(nil).GetTodo), arg0, arg1)
 }
 
+// GetUser mocks base method.
+func (m *MockRepo) GetUser(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockRepoMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockRepo)(nil).GetUser), arg0, arg1)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockRepo) GetUserByUsername(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockRepoMockRecorder) GetUserByUsername(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockRepo)(nil).GetUserByUsername), arg0, arg1)
+}
+
+// ListAccessTokens mocks base method.
+func (m *MockRepo) ListAccessTokens(arg0 context.Context, arg1 int64) ([]db.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccessTokens", arg0, arg1)
+	ret0, _ := ret[0].([]db.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccessTokens indicates an expected call of ListAccessTokens.
+func (mr *MockRepoMockRecorder) ListAccessTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccessTokens", reflect.TypeOf((*MockRepo)(nil).ListAccessTokens), arg0, arg1)
+}
+
+// ListDeletedTodo mocks base method.
+func (m *MockRepo) ListDeletedTodo(arg0 context.Context, arg1 db.ListDeletedTodoParams) ([]db.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeletedTodo", arg0, arg1)
+	ret0, _ := ret[0].([]db.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeletedTodo indicates an expected call of ListDeletedTodo.
+func (mr *MockRepoMockRecorder) ListDeletedTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeletedTodo", reflect.TypeOf((*MockRepo)(nil).ListDeletedTodo), arg0, arg1)
+}
+
+// ListTags mocks base method.
+func (m *MockRepo) ListTags(arg0 context.Context, arg1 int64) ([]db.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTags", arg0, arg1)
+	ret0, _ := ret[0].([]db.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTags indicates an expected call of ListTags.
+func (mr *MockRepoMockRecorder) ListTags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockRepo)(nil).ListTags), arg0, arg1)
+}
+
+// ListTagsByTodo mocks base method.
+func (m *MockRepo) ListTagsByTodo(arg0 context.Context, arg1 db.ListTagsByTodoParams) ([]db.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTagsByTodo", arg0, arg1)
+	ret0, _ := ret[0].([]db.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTagsByTodo indicates an expected call of ListTagsByTodo.
+func (mr *MockRepoMockRecorder) ListTagsByTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagsByTodo", reflect.TypeOf((*MockRepo)(nil).ListTagsByTodo), arg0, arg1)
+}
+
+// ListTagsByTodoIDs mocks base method.
+func (m *MockRepo) ListTagsByTodoIDs(arg0 context.Context, arg1 db.ListTagsByTodoIDsParams) (map[int64][]db.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTagsByTodoIDs", arg0, arg1)
+	ret0, _ := ret[0].(map[int64][]db.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTagsByTodoIDs indicates an expected call of ListTagsByTodoIDs.
+func (mr *MockRepoMockRecorder) ListTagsByTodoIDs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagsByTodoIDs", reflect.TypeOf((*MockRepo)(nil).ListTagsByTodoIDs), arg0, arg1)
+}
+
 // ListTodo mocks base method.
 func (m *MockRepo) ListTodo(arg0 context.Context, arg1 db.ListTodoParams) ([]db.Todo, error) {
 	m.ctrl.T.Helper()
@@ -138,6 +406,122 @@ func (mr *MockRepoMockRecorder) ListTodo(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTodo", reflect.TypeOf((*MockRepo)(nil).ListTodo), arg0, arg1)
 }
 
+// Ping mocks base method.
+func (m *MockRepo) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockRepoMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockRepo)(nil).Ping), arg0)
+}
+
+// PurgeDeletedTodo mocks base method.
+func (m *MockRepo) PurgeDeletedTodo(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedTodo", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedTodo indicates an expected call of PurgeDeletedTodo.
+func (mr *MockRepoMockRecorder) PurgeDeletedTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedTodo", reflect.TypeOf((*MockRepo)(nil).PurgeDeletedTodo), arg0, arg1)
+}
+
+// PurgeTodo mocks base method.
+func (m *MockRepo) PurgeTodo(arg0 context.Context, arg1 db.PurgeTodoParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTodo", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTodo indicates an expected call of PurgeTodo.
+func (mr *MockRepoMockRecorder) PurgeTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTodo", reflect.TypeOf((*MockRepo)(nil).PurgeTodo), arg0, arg1)
+}
+
+// ReplaceTodoTagsTx mocks base method.
+func (m *MockRepo) ReplaceTodoTagsTx(arg0 context.Context, arg1 db.ReplaceTodoTagsTxParams) ([]db.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceTodoTagsTx", arg0, arg1)
+	ret0, _ := ret[0].([]db.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceTodoTagsTx indicates an expected call of ReplaceTodoTagsTx.
+func (mr *MockRepoMockRecorder) ReplaceTodoTagsTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceTodoTagsTx", reflect.TypeOf((*MockRepo)(nil).ReplaceTodoTagsTx), arg0, arg1)
+}
+
+// RestoreTodo mocks base method.
+func (m *MockRepo) RestoreTodo(arg0 context.Context, arg1 db.RestoreTodoParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTodo", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreTodo indicates an expected call of RestoreTodo.
+func (mr *MockRepoMockRecorder) RestoreTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTodo", reflect.TypeOf((*MockRepo)(nil).RestoreTodo), arg0, arg1)
+}
+
+// RevokeAccessToken mocks base method.
+func (m *MockRepo) RevokeAccessToken(arg0 context.Context, arg1 db.RevokeAccessTokenParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccessToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccessToken indicates an expected call of RevokeAccessToken.
+func (mr *MockRepoMockRecorder) RevokeAccessToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccessToken", reflect.TypeOf((*MockRepo)(nil).RevokeAccessToken), arg0, arg1)
+}
+
+// ToggleTodo mocks base method.
+func (m *MockRepo) ToggleTodo(arg0 context.Context, arg1 db.ToggleTodoParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ToggleTodo", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ToggleTodo indicates an expected call of ToggleTodo.
+func (mr *MockRepoMockRecorder) ToggleTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToggleTodo", reflect.TypeOf((*MockRepo)(nil).ToggleTodo), arg0, arg1)
+}
+
+// TouchAccessToken mocks base method.
+func (m *MockRepo) TouchAccessToken(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAccessToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAccessToken indicates an expected call of TouchAccessToken.
+func (mr *MockRepoMockRecorder) TouchAccessToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAccessToken", reflect.TypeOf((*MockRepo)(nil).TouchAccessToken), arg0, arg1)
+}
+
 // UpdateTodo mocks base method.
 func (m *MockRepo) UpdateTodo(arg0 context.Context, arg1 db.UpdateTodoParams) error {
 	m.ctrl.T.Helper()
@@ -151,3 +535,18 @@ func (mr *MockRepoMockRecorder) UpdateTodo(arg0, arg1 interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTodo", reflect.TypeOf((*MockRepo)(nil).UpdateTodo), arg0, arg1)
 }
+
+// UpsertTag mocks base method.
+func (m *MockRepo) UpsertTag(arg0 context.Context, arg1 db.UpsertTagParams) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertTag", arg0, arg1)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertTag indicates an expected call of UpsertTag.
+func (mr *MockRepoMockRecorder) UpsertTag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTag", reflect.TypeOf((*MockRepo)(nil).UpsertTag), arg0, arg1)
+}
@@ -0,0 +1,23 @@
+-- name: CreateAccessToken :execresult
+INSERT INTO access_tokens (
+  user_id, name, token_hash, scopes, expires_at
+) VALUES (
+  ?, ?, ?, ?, ?
+);
+
+-- name: GetAccessTokenByHash :one
+SELECT * FROM access_tokens
+WHERE token_hash = ? LIMIT 1;
+
+-- name: ListAccessTokens :many
+SELECT * FROM access_tokens
+WHERE user_id = ?
+ORDER BY id DESC;
+
+-- name: RevokeAccessToken :exec
+UPDATE access_tokens SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = ? AND user_id = ? AND revoked_at IS NULL;
+
+-- name: TouchAccessToken :exec
+UPDATE access_tokens SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = ?;
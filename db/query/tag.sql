@@ -0,0 +1,45 @@
+-- name: CreateTag :execresult
+INSERT INTO tags (
+  user_id, name
+) VALUES (
+  ?, ?
+);
+
+-- name: GetTagByName :one
+SELECT * FROM tags
+WHERE user_id = ? AND name = ? LIMIT 1;
+
+-- name: ListTags :many
+SELECT * FROM tags
+WHERE user_id = ?
+ORDER BY name;
+
+-- name: ListTagsByTodo :many
+SELECT tags.* FROM tags
+JOIN todo_tags ON todo_tags.tag_id = tags.id
+WHERE todo_tags.todo_id = ? AND tags.user_id = ?
+ORDER BY tags.name;
+
+-- name: AttachTag :exec
+INSERT IGNORE INTO todo_tags (
+  todo_id, tag_id
+) VALUES (
+  ?, ?
+);
+
+-- name: DetachAllTags :exec
+DELETE FROM todo_tags
+WHERE todo_id = ?;
+
+-- name: DetachTag :exec
+DELETE FROM todo_tags
+WHERE todo_id = ? AND tag_id IN (
+  SELECT id FROM tags WHERE id = sqlc.arg('tag_id') AND user_id = ?
+);
+
+-- name: UpsertTag :execresult
+INSERT INTO tags (
+  user_id, name
+) VALUES (
+  ?, ?
+) ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id);
\ No newline at end of file
@@ -1,33 +1,48 @@
 -- name: CreateTodo :execresult
 INSERT INTO Todo (
-  title, description
+  user_id, title, description, priority, due_at
 ) VALUES (
-  ?, ?
+  ?, ?, ?, ?, ?
 );
 
 -- name: GetTodo :one
 SELECT * FROM Todo
-WHERE id = ? LIMIT 1;
-
--- name: ListTodo :many
-SELECT * FROM Todo
-ORDER BY id
-LIMIT ?, ?;
+WHERE id = ? AND user_id = ? AND deleted_at IS NULL LIMIT 1;
 
 -- name: UpdateTodo :exec
-UPDATE Todo SET description = ?
-WHERE id = ?;
+UPDATE Todo SET description = ?, status = ?, priority = ?, due_at = ?
+WHERE id = ? AND user_id = ? AND deleted_at IS NULL;
+
+-- name: ToggleTodo :exec
+UPDATE Todo SET status = CASE WHEN status = 'done' THEN 'open' ELSE 'done' END
+WHERE id = ? AND user_id = ? AND deleted_at IS NULL;
 
 -- name: DeleteTodo :exec
-DELETE FROM Todo
-WHERE id = ?;
+UPDATE Todo SET deleted_at = CURRENT_TIMESTAMP, update_date = update_date
+WHERE id = ? AND user_id = ? AND deleted_at IS NULL;
 
--- name: DeleteTodoList :exec
+-- name: ClearTodo :exec
 DELETE FROM Todo
-WHERE FIND_IN_SET(id, sqlc.arg('ids'));
+WHERE user_id = ?;
+
+-- name: ListDeletedTodo :many
+SELECT * FROM Todo
+WHERE user_id = ? AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC
+LIMIT ?, ?;
 
--- name: CountTodo :one
-SELECT count(*) FROM Todo;
+-- name: CountDeletedTodo :one
+SELECT count(*) FROM Todo
+WHERE user_id = ? AND deleted_at IS NOT NULL;
 
--- name: ClearTodo :exec
-TRUNCATE TABLE Todo;
+-- name: RestoreTodo :execrows
+UPDATE Todo SET deleted_at = NULL, update_date = update_date
+WHERE id = ? AND user_id = ? AND deleted_at IS NOT NULL;
+
+-- name: PurgeTodo :execrows
+DELETE FROM Todo
+WHERE id = ? AND user_id = ? AND deleted_at IS NOT NULL;
+
+-- name: PurgeDeletedTodo :execrows
+DELETE FROM Todo
+WHERE deleted_at IS NOT NULL AND deleted_at < CURRENT_TIMESTAMP - INTERVAL sqlc.arg(retention_seconds) SECOND;
\ No newline at end of file
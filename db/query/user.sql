@@ -0,0 +1,14 @@
+-- name: CreateUser :execresult
+INSERT INTO users (
+  username, hashed_password
+) VALUES (
+  ?, ?
+);
+
+-- name: GetUser :one
+SELECT * FROM users
+WHERE id = ? LIMIT 1;
+
+-- name: GetUserByUsername :one
+SELECT * FROM users
+WHERE username = ? LIMIT 1;
@@ -0,0 +1,385 @@
+// Package repotest is the conformance suite of the db.Repo implementations.
+package repotest
+
+import (
+	"context"
+	"database/sql"
+	db "first-app/todo_go/db/sqlc"
+	"first-app/todo_go/util"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Run checks the behaviour every db.Repo implementation has to share on
+// repo, whatever the storage behind it. The implementations keep times with
+// different precisions, so times are compared to the second.
+func Run(t *testing.T, repo db.Repo) {
+	ctx := context.Background()
+
+	createUser := func(t *testing.T) db.User {
+		result, err := repo.CreateUser(ctx, db.CreateUserParams{
+			Username:       util.RandomUsername(),
+			HashedPassword: util.RandomString(60),
+		})
+		require.NoError(t, err)
+		id, err := result.LastInsertId()
+		require.NoError(t, err)
+
+		user, err := repo.GetUser(ctx, id)
+		require.NoError(t, err)
+		return user
+	}
+	createTodo := func(t *testing.T, user db.User, title string) db.Todo {
+		result, err := repo.CreateTodoTx(ctx, db.CreateTodoTxParams{
+			CreateTodoParams: db.CreateTodoParams{
+				UserID:      user.ID,
+				Title:       sql.NullString{String: title, Valid: true},
+				Description: util.RandomDescription(),
+				Priority:    db.TodoPriorityMedium,
+			},
+		})
+		require.NoError(t, err)
+		return result.Todo
+	}
+
+	t.Run("Users", func(t *testing.T) {
+		user := createUser(t)
+		require.NotZero(t, user.ID)
+		require.True(t, user.CreateDate.Valid)
+
+		byName, err := repo.GetUserByUsername(ctx, user.Username)
+		require.NoError(t, err)
+		require.Equal(t, user, byName)
+
+		_, err = repo.CreateUser(ctx, db.CreateUserParams{Username: user.Username, HashedPassword: "x"})
+		require.ErrorIs(t, err, db.ErrDuplicate)
+
+		_, err = repo.GetUserByUsername(ctx, util.RandomUsername())
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("Todos", func(t *testing.T) {
+		user := createUser(t)
+		dueAt := time.Now().Add(24 * time.Hour).UTC()
+
+		result, err := repo.CreateTodo(ctx, db.CreateTodoParams{
+			UserID:   user.ID,
+			Title:    sql.NullString{String: "Buy milk", Valid: true},
+			Priority: db.TodoPriorityHigh,
+			DueAt:    sql.NullTime{Time: dueAt, Valid: true},
+		})
+		require.NoError(t, err)
+		id, err := result.LastInsertId()
+		require.NoError(t, err)
+
+		todo, err := repo.GetTodo(ctx, db.GetTodoParams{ID: id, UserID: user.ID})
+		require.NoError(t, err)
+		require.Equal(t, "Buy milk", todo.Title.String)
+		require.False(t, todo.Description.Valid)
+		require.Equal(t, db.TodoStatusOpen, todo.Status)
+		require.Equal(t, db.TodoPriorityHigh, todo.Priority)
+		require.WithinDuration(t, dueAt, todo.DueAt.Time, time.Second)
+		require.True(t, todo.CreateDate.Valid)
+		require.False(t, todo.UpdateDate.Valid)
+
+		_, err = repo.GetTodo(ctx, db.GetTodoParams{ID: id, UserID: user.ID + 1})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		err = repo.UpdateTodo(ctx, db.UpdateTodoParams{
+			Description: sql.NullString{String: "Two bottles", Valid: true},
+			Status:      db.TodoStatusInProgress,
+			Priority:    db.TodoPriorityLow,
+			ID:          id,
+			UserID:      user.ID,
+		})
+		require.NoError(t, err)
+		todo, err = repo.GetTodo(ctx, db.GetTodoParams{ID: id, UserID: user.ID})
+		require.NoError(t, err)
+		require.Equal(t, "Two bottles", todo.Description.String)
+		require.Equal(t, db.TodoStatusInProgress, todo.Status)
+		require.Equal(t, db.TodoPriorityLow, todo.Priority)
+		require.False(t, todo.DueAt.Valid)
+		require.True(t, todo.UpdateDate.Valid)
+
+		for _, want := range []db.TodoStatus{db.TodoStatusDone, db.TodoStatusOpen} {
+			require.NoError(t, repo.ToggleTodo(ctx, db.ToggleTodoParams{ID: id, UserID: user.ID}))
+			todo, err = repo.GetTodo(ctx, db.GetTodoParams{ID: id, UserID: user.ID})
+			require.NoError(t, err)
+			require.Equal(t, want, todo.Status)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		user := createUser(t)
+		milk := createTodo(t, user, "Buy milk")
+		bread := createTodo(t, user, "Bake bread")
+		apples := createTodo(t, user, "Pick apples")
+		createTodo(t, createUser(t), "Buy milk")
+
+		filter := db.TodoFilter{UserID: user.ID}
+		count, err := repo.CountTodo(ctx, filter)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), count)
+
+		todos, err := repo.ListTodo(ctx, db.ListTodoParams{TodoFilter: filter, SortBy: db.TodoSortTitle, SortOrder: db.SortAsc, Offset: 1, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, todos, 2)
+		require.Equal(t, milk.ID, todos[0].ID)
+		require.Equal(t, apples.ID, todos[1].ID)
+
+		todos, err = repo.ListTodo(ctx, db.ListTodoParams{TodoFilter: filter, SortBy: db.TodoSortID, SortOrder: db.SortDesc, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, todos, 1)
+		require.Equal(t, apples.ID, todos[0].ID)
+
+		search := db.TodoFilter{UserID: user.ID, Query: "bread"}
+		count, err = repo.CountTodo(ctx, search)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+		todos, err = repo.ListTodo(ctx, db.ListTodoParams{TodoFilter: search, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, todos, 1)
+		require.Equal(t, bread.ID, todos[0].ID)
+
+		created := db.TodoFilter{UserID: user.ID, CreatedFrom: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}}
+		count, err = repo.CountTodo(ctx, created)
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		user := createUser(t)
+		for _, title := range []string{"b", "a", "b", "c"} {
+			todo := createTodo(t, user, title)
+			err := repo.UpdateTodo(ctx, db.UpdateTodoParams{
+				ID:          todo.ID,
+				UserID:      user.ID,
+				Description: sql.NullString{String: "updated " + title, Valid: true},
+				Status:      db.TodoStatusOpen,
+				Priority:    db.TodoPriorityMedium,
+			})
+			require.NoError(t, err)
+		}
+		// The last two are never updated, and the NULL title and update dates
+		// sort before every value.
+		createTodo(t, user, "")
+		_, err := repo.CreateTodoTx(ctx, db.CreateTodoTxParams{
+			CreateTodoParams: db.CreateTodoParams{UserID: user.ID, Priority: db.TodoPriorityMedium},
+		})
+		require.NoError(t, err)
+		filter := db.TodoFilter{UserID: user.ID}
+
+		for _, sort := range []struct {
+			by    db.TodoSortField
+			order db.SortOrder
+		}{
+			{db.TodoSortID, db.SortAsc},
+			{db.TodoSortID, db.SortDesc},
+			{db.TodoSortTitle, db.SortAsc},
+			{db.TodoSortTitle, db.SortDesc},
+			{db.TodoSortUpdateDate, db.SortAsc},
+			{db.TodoSortUpdateDate, db.SortDesc},
+		} {
+			arg := db.ListTodoParams{TodoFilter: filter, SortBy: sort.by, SortOrder: sort.order, Limit: 100}
+			all, err := repo.ListTodo(ctx, arg)
+			require.NoError(t, err)
+			require.Len(t, all, 6)
+			want := make([]int64, len(all))
+			for i, todo := range all {
+				want[i] = todo.ID
+			}
+
+			// Forward through the pages of two, then back from the last one.
+			arg.Limit = 2
+			var pages [][]int64
+			page, err := db.ListTodoPage(ctx, repo, arg)
+			require.NoError(t, err)
+			require.Nil(t, page.Prev)
+			for {
+				ids := []int64{}
+				for _, todo := range page.Todos {
+					ids = append(ids, todo.ID)
+				}
+				pages = append(pages, ids)
+				if page.Next == nil {
+					break
+				}
+				arg.Cursor = page.Next
+				page, err = db.ListTodoPage(ctx, repo, arg)
+				require.NoError(t, err)
+			}
+			require.Len(t, pages, 3, "%s %s", sort.by, sort.order)
+			var got []int64
+			for _, ids := range pages {
+				got = append(got, ids...)
+			}
+			require.Equal(t, want, got, "%s %s", sort.by, sort.order)
+
+			for i := len(pages) - 2; i >= 0; i-- {
+				require.NotNil(t, page.Prev)
+				arg.Cursor = page.Prev
+				page, err = db.ListTodoPage(ctx, repo, arg)
+				require.NoError(t, err)
+				ids := []int64{}
+				for _, todo := range page.Todos {
+					ids = append(ids, todo.ID)
+				}
+				require.Equal(t, pages[i], ids, "%s %s", sort.by, sort.order)
+			}
+			require.Nil(t, page.Prev)
+		}
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		user := createUser(t)
+		result, err := repo.CreateTodoTx(ctx, db.CreateTodoTxParams{
+			CreateTodoParams: db.CreateTodoParams{UserID: user.ID, Priority: db.TodoPriorityMedium},
+			TagNames:         []string{"work", "home", "work"},
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Tags, 2)
+		require.Equal(t, "home", result.Tags[0].Name)
+		require.Equal(t, "work", result.Tags[1].Name)
+
+		upserted, err := repo.UpsertTag(ctx, db.UpsertTagParams{UserID: user.ID, Name: "work"})
+		require.NoError(t, err)
+		id, err := upserted.LastInsertId()
+		require.NoError(t, err)
+		require.Equal(t, result.Tags[1].ID, id)
+
+		_, err = repo.CreateTag(ctx, db.CreateTagParams{UserID: user.ID, Name: "home"})
+		require.ErrorIs(t, err, db.ErrDuplicate)
+
+		tags, err := repo.ReplaceTodoTagsTx(ctx, db.ReplaceTodoTagsTxParams{
+			TodoID:   result.Todo.ID,
+			UserID:   user.ID,
+			TagNames: []string{"urgent", "home"},
+		})
+		require.NoError(t, err)
+		require.Len(t, tags, 2)
+		require.Equal(t, "home", tags[0].Name)
+		require.Equal(t, "urgent", tags[1].Name)
+
+		err = repo.DetachTag(ctx, db.DetachTagParams{TodoID: result.Todo.ID, TagID: tags[0].ID, UserID: user.ID})
+		require.NoError(t, err)
+		byTodo, err := repo.ListTagsByTodoIDs(ctx, db.ListTagsByTodoIDsParams{UserID: user.ID, TodoIDs: []int64{result.Todo.ID}})
+		require.NoError(t, err)
+		require.Len(t, byTodo[result.Todo.ID], 1)
+		require.Equal(t, "urgent", byTodo[result.Todo.ID][0].Name)
+
+		all, err := repo.ListTags(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, all, 3)
+
+		count, err := repo.CountTodo(ctx, db.TodoFilter{UserID: user.ID, Tag: "urgent"})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+
+		_, err = repo.ReplaceTodoTagsTx(ctx, db.ReplaceTodoTagsTxParams{
+			TodoID:   result.Todo.ID,
+			UserID:   createUser(t).ID,
+			TagNames: []string{"stolen"},
+		})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("Trash", func(t *testing.T) {
+		user := createUser(t)
+		first := createTodo(t, user, "First")
+		second := createTodo(t, user, "Second")
+		third := createTodo(t, user, "Third")
+
+		deleted, err := repo.DeleteTodoListTx(ctx, db.DeleteTodoListParams{UserID: user.ID, IDs: []int64{first.ID, second.ID, 0}})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted.Deleted)
+		require.Equal(t, []int64{0}, deleted.NotFound)
+
+		require.NoError(t, repo.DeleteTodo(ctx, db.DeleteTodoParams{ID: third.ID, UserID: user.ID}))
+		_, err = repo.GetTodo(ctx, db.GetTodoParams{ID: third.ID, UserID: user.ID})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		count, err := repo.CountDeletedTodo(ctx, user.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), count)
+		trash, err := repo.ListDeletedTodo(ctx, db.ListDeletedTodoParams{UserID: user.ID, Offset: 1, Limit: 5})
+		require.NoError(t, err)
+		require.Len(t, trash, 2)
+		for _, todo := range trash {
+			require.True(t, todo.DeletedAt.Valid)
+		}
+
+		restored, err := repo.RestoreTodo(ctx, db.RestoreTodoParams{ID: first.ID, UserID: user.ID})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), restored)
+		// Moving a todo to the trash and back is not an edit.
+		got, err := repo.GetTodo(ctx, db.GetTodoParams{ID: first.ID, UserID: user.ID})
+		require.NoError(t, err)
+		require.Equal(t, first.UpdateDate, got.UpdateDate)
+		purged, err := repo.PurgeTodo(ctx, db.PurgeTodoParams{ID: first.ID, UserID: user.ID})
+		require.NoError(t, err)
+		require.Zero(t, purged)
+		purged, err = repo.PurgeTodo(ctx, db.PurgeTodoParams{ID: second.ID, UserID: user.ID})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), purged)
+
+		purged, err = repo.PurgeDeletedTodo(ctx, 3600)
+		require.NoError(t, err)
+		require.Zero(t, purged)
+
+		// A negative retention reaches todos trashed within the last second.
+		purged, err = repo.PurgeDeletedTodo(ctx, -60)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, purged, int64(1))
+		count, err = repo.CountDeletedTodo(ctx, user.ID)
+		require.NoError(t, err)
+		require.Zero(t, count)
+
+		require.NoError(t, repo.ClearTodo(ctx, user.ID))
+		count, err = repo.CountTodo(ctx, db.TodoFilter{UserID: user.ID})
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+
+	t.Run("Access Tokens", func(t *testing.T) {
+		user := createUser(t)
+		expiresAt := time.Now().Add(time.Hour).UTC()
+		result, err := repo.CreateAccessToken(ctx, db.CreateAccessTokenParams{
+			UserID:    user.ID,
+			Name:      "cli",
+			TokenHash: util.RandomString(64),
+			Scopes:    "todos:read",
+			ExpiresAt: sql.NullTime{Time: expiresAt, Valid: true},
+		})
+		require.NoError(t, err)
+		id, err := result.LastInsertId()
+		require.NoError(t, err)
+
+		tokens, err := repo.ListAccessTokens(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		token := tokens[0]
+		require.Equal(t, id, token.ID)
+		require.WithinDuration(t, expiresAt, token.ExpiresAt.Time, time.Second)
+
+		byHash, err := repo.GetAccessTokenByHash(ctx, token.TokenHash)
+		require.NoError(t, err)
+		require.Equal(t, token, byHash)
+
+		require.NoError(t, repo.TouchAccessToken(ctx, id))
+		require.NoError(t, repo.RevokeAccessToken(ctx, db.RevokeAccessTokenParams{ID: id, UserID: user.ID}))
+		token, err = repo.GetAccessTokenByHash(ctx, token.TokenHash)
+		require.NoError(t, err)
+		require.True(t, token.LastUsedAt.Valid)
+		require.True(t, token.RevokedAt.Valid)
+	})
+
+	t.Run("Health", func(t *testing.T) {
+		require.NoError(t, repo.Ping(ctx))
+		version, err := repo.GetSchemaVersion(ctx)
+		require.NoError(t, err)
+		require.NotZero(t, version.Version)
+		require.False(t, version.Dirty)
+	})
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"first-app/todo_go/util"
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4/database"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// backend holds what differs between the databases DB_DRIVER selects.
+type backend struct {
+	// driverName is the database/sql driver the connections are opened with.
+	driverName string
+	dialect    *dialect
+	dsn        func(config util.Config) (string, error)
+	// migrationDSN is the data source name of the migrator connection.
+	migrationDSN func(config util.Config) (string, error)
+	// migrationDriver hands the migrator connection to golang-migrate.
+	migrationDriver func(conn *sql.DB) (database.Driver, error)
+}
+
+// backends are the supported DB_DRIVER values. SQLite is registered by
+// sqlite.go, only built with the sqlite tag.
+var backends = map[string]backend{
+	"mysql": {
+		driverName:      "mysql",
+		dialect:         mysqlDialect,
+		dsn:             mysqlDSN,
+		migrationDSN:    mysqlMigrationDSN,
+		migrationDriver: mysqlMigrationDriver,
+	},
+	"postgres": {
+		driverName:      "pgx",
+		dialect:         postgresDialect,
+		dsn:             postgresDSN,
+		migrationDSN:    postgresDSN,
+		migrationDriver: postgresMigrationDriver,
+	},
+}
+
+func backendFor(driver string) (backend, error) {
+	b, ok := backends[driver]
+	if ok {
+		return b, nil
+	}
+	if driver == "sqlite" {
+		return backend{}, errors.New("DB_DRIVER sqlite needs a binary built with -tags sqlite")
+	}
+	return backend{}, fmt.Errorf("invalid DB_DRIVER %q, expected mysql, postgres or sqlite", driver)
+}
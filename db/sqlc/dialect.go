@@ -0,0 +1,210 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrDuplicate is wrapped into the errors of the inserts that break a unique
+// key, whatever the database.
+var ErrDuplicate = errors.New("duplicate entry")
+
+const (
+	mysqlDuplicateEntry     = 1062
+	postgresUniqueViolation = "23505"
+	sqliteTimeLayout        = "2006-01-02 15:04:05"
+)
+
+// dialect adapts the queries, written for MySQL like sqlc generates them, to
+// the database the repo runs on.
+type dialect struct {
+	// replacements swap MySQL syntax for the dialect's own, in order.
+	replacements []string
+	// queries replace whole queries, by sqlc name.
+	queries map[string]string
+	// returningID lists the inserts that read the new id back with RETURNING,
+	// for drivers without LastInsertId or upserts that do not set it.
+	returningID map[string]bool
+	// numbered switches the ? placeholders for $1, $2...
+	numbered bool
+	// convertArg, when set, converts each argument before it is sent.
+	convertArg  func(arg interface{}) interface{}
+	isDuplicate func(err error) bool
+}
+
+var mysqlDialect = &dialect{
+	isDuplicate: func(err error) bool {
+		var mysqlErr *mysql.MySQLError
+		return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry
+	},
+}
+
+// insertedIDs are the sqlc inserts whose callers read LastInsertId.
+var insertedIDs = map[string]bool{
+	"CreateAccessToken": true,
+	"CreateTag":         true,
+	"CreateTodo":        true,
+	"CreateUser":        true,
+	"UpsertTag":         true,
+}
+
+// ANSI versions of the tag queries MySQL writes with its own upsert syntax.
+var ansiTagQueries = map[string]string{
+	"AttachTag": "INSERT INTO todo_tags (todo_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+	"UpsertTag": "INSERT INTO tags (user_id, name) VALUES (?, ?) ON CONFLICT (user_id, name) DO UPDATE SET name = excluded.name",
+}
+
+var postgresDialect = &dialect{
+	replacements: []string{
+		searchCondition, "to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(description, '')) @@ plainto_tsquery('simple', ?)",
+		"LIMIT ?, ?", "OFFSET ? LIMIT ?",
+		"INTERVAL ? SECOND", "make_interval(secs => ?)",
+		// PostgreSQL sorts NULLs as the largest value, MySQL as the smallest.
+		" ASC, id ASC", " ASC NULLS FIRST, id ASC",
+		" DESC, id DESC", " DESC NULLS LAST, id DESC",
+	},
+	queries:     ansiTagQueries,
+	returningID: insertedIDs,
+	numbered:    true,
+	isDuplicate: func(err error) bool {
+		var pgErr *pgconn.PgError
+		return errors.As(err, &pgErr) && pgErr.Code == postgresUniqueViolation
+	},
+}
+
+// sqliteDialect serializes the writers with immediate transactions, see
+// sqliteDSN, so the row locks of FOR UPDATE are not needed.
+var sqliteDialect = &dialect{
+	replacements: []string{
+		searchCondition, "instr(lower(coalesce(title, '') || ' ' || coalesce(description, '')), lower(?)) > 0",
+		" FOR UPDATE", "",
+		"CURRENT_TIMESTAMP - INTERVAL ? SECOND", "datetime('now', -? || ' seconds')",
+	},
+	queries:     ansiTagQueries,
+	returningID: insertedIDs,
+	convertArg:  sqliteArg,
+	isDuplicate: func(err error) bool {
+		return strings.Contains(err.Error(), "UNIQUE constraint failed")
+	},
+}
+
+// sqliteArg stores times in UTC and in the format of CURRENT_TIMESTAMP, so
+// that the dates compare as text like the ones SQLite writes itself.
+func sqliteArg(arg interface{}) interface{} {
+	if valuer, ok := arg.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return arg
+		}
+		arg = value
+	}
+	if t, ok := arg.(time.Time); ok {
+		return t.UTC().Format(sqliteTimeLayout)
+	}
+	return arg
+}
+
+// rewrite returns the query and arguments to run on the dialect's database.
+func (d *dialect) rewrite(query string, args []interface{}) (string, []interface{}) {
+	if override, ok := d.queries[queryName(query)]; ok {
+		query = override
+	}
+	for i := 0; i+1 < len(d.replacements); i += 2 {
+		query = strings.ReplaceAll(query, d.replacements[i], d.replacements[i+1])
+	}
+	if d.numbered {
+		query = numberPlaceholders(query)
+	}
+	if d.convertArg != nil {
+		converted := make([]interface{}, len(args))
+		for i, arg := range args {
+			converted[i] = d.convertArg(arg)
+		}
+		args = converted
+	}
+	return query, args
+}
+
+// numberPlaceholders turns the ? placeholders outside of string literals into
+// $1, $2...
+func numberPlaceholders(query string) string {
+	var b strings.Builder
+	n := 0
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == '?' && !quoted:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// translate wraps ErrDuplicate into the unique key violations.
+func (d *dialect) translate(err error) error {
+	if err != nil && d.isDuplicate(err) {
+		return fmt.Errorf("%w: %w", ErrDuplicate, err)
+	}
+	return err
+}
+
+// insertResult is the result of an insert that read its id back.
+type insertResult int64
+
+func (r insertResult) LastInsertId() (int64, error) {
+	return int64(r), nil
+}
+
+func (r insertResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
+
+// dialectDBTX runs the queries on db once rewritten for its dialect.
+type dialectDBTX struct {
+	db      DBTX
+	dialect *dialect
+}
+
+func (d dialectDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	name := queryName(query)
+	query, args = d.dialect.rewrite(query, args)
+	if d.dialect.returningID[name] {
+		var id int64
+		err := d.db.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+		if err != nil {
+			return nil, d.dialect.translate(err)
+		}
+		return insertResult(id), nil
+	}
+	result, err := d.db.ExecContext(ctx, query, args...)
+	return result, d.dialect.translate(err)
+}
+
+func (d dialectDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	query, _ = d.dialect.rewrite(query, nil)
+	return d.db.PrepareContext(ctx, query)
+}
+
+func (d dialectDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query, args = d.dialect.rewrite(query, args)
+	return d.db.QueryContext(ctx, query, args...)
+}
+
+func (d dialectDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query, args = d.dialect.rewrite(query, args)
+	return d.db.QueryRowContext(ctx, query, args...)
+}
@@ -0,0 +1,161 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialectRewrite(t *testing.T) {
+	now := time.Date(2024, 5, 1, 21, 30, 15, 500, time.FixedZone("JST", 9*60*60))
+	filter := TodoFilter{UserID: 1, Query: "milk"}
+	listQuery, listArgs := buildListTodo(ListTodoParams{TodoFilter: filter, Offset: 10, Limit: 5})
+	lockQuery, lockArgs := buildLockTodoList(DeleteTodoListParams{UserID: 1, IDs: []int64{2, 3}})
+
+	testCases := []struct {
+		name      string
+		dialect   *dialect
+		query     string
+		args      []interface{}
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "MySQL",
+			dialect:   mysqlDialect,
+			query:     listQuery,
+			args:      listArgs,
+			wantQuery: listQuery,
+			wantArgs:  listArgs,
+		},
+		{
+			name:    "PostgreSQL List",
+			dialect: postgresDialect,
+			query:   listQuery,
+			args:    listArgs,
+			wantQuery: "SELECT " + todoColumns + " FROM Todo WHERE user_id = $1 AND deleted_at IS NULL" +
+				" AND to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(description, '')) @@ plainto_tsquery('simple', $2)" +
+				" ORDER BY id ASC OFFSET $3 LIMIT $4",
+			wantArgs: listArgs,
+		},
+		{
+			name:      "PostgreSQL NULLs",
+			dialect:   postgresDialect,
+			query:     "SELECT id FROM Todo ORDER BY title ASC, id ASC",
+			wantQuery: "SELECT id FROM Todo ORDER BY title ASC NULLS FIRST, id ASC",
+		},
+		{
+			name:      "PostgreSQL Quoted",
+			dialect:   postgresDialect,
+			query:     toggleTodo,
+			args:      []interface{}{int64(1), int64(2)},
+			wantQuery: "-- name: ToggleTodo :exec\nUPDATE Todo SET status = CASE WHEN status = 'done' THEN 'open' ELSE 'done' END\nWHERE id = $1 AND user_id = $2 AND deleted_at IS NULL\n",
+			wantArgs:  []interface{}{int64(1), int64(2)},
+		},
+		{
+			name:      "PostgreSQL Interval",
+			dialect:   postgresDialect,
+			query:     purgeDeletedTodo,
+			args:      []interface{}{int64(3600)},
+			wantQuery: "-- name: PurgeDeletedTodo :execrows\nDELETE FROM Todo\nWHERE deleted_at IS NOT NULL AND deleted_at < CURRENT_TIMESTAMP - make_interval(secs => $1)\n",
+			wantArgs:  []interface{}{int64(3600)},
+		},
+		{
+			name:      "PostgreSQL Upsert",
+			dialect:   postgresDialect,
+			query:     upsertTag,
+			args:      []interface{}{int64(1), "home"},
+			wantQuery: "INSERT INTO tags (user_id, name) VALUES ($1, $2) ON CONFLICT (user_id, name) DO UPDATE SET name = excluded.name",
+			wantArgs:  []interface{}{int64(1), "home"},
+		},
+		{
+			name:      "SQLite Lock",
+			dialect:   sqliteDialect,
+			query:     lockQuery,
+			args:      lockArgs,
+			wantQuery: "SELECT id FROM Todo WHERE user_id = ? AND deleted_at IS NULL AND id IN (?, ?)",
+			wantArgs:  lockArgs,
+		},
+		{
+			name:      "SQLite Interval",
+			dialect:   sqliteDialect,
+			query:     purgeDeletedTodo,
+			args:      []interface{}{int64(3600)},
+			wantQuery: "-- name: PurgeDeletedTodo :execrows\nDELETE FROM Todo\nWHERE deleted_at IS NOT NULL AND deleted_at < datetime('now', -? || ' seconds')\n",
+			wantArgs:  []interface{}{int64(3600)},
+		},
+		{
+			name:      "SQLite Times",
+			dialect:   sqliteDialect,
+			query:     "SELECT id FROM Todo WHERE deleted_at < ? AND update_date < ? AND deleted_at = ?",
+			args:      []interface{}{now, sql.NullTime{Time: now, Valid: true}, sql.NullTime{}},
+			wantQuery: "SELECT id FROM Todo WHERE deleted_at < ? AND update_date < ? AND deleted_at = ?",
+			wantArgs:  []interface{}{"2024-05-01 12:30:15", "2024-05-01 12:30:15", nil},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			query, args := tc.dialect.rewrite(tc.query, tc.args)
+			require.Equal(t, tc.wantQuery, query)
+			require.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
+
+func TestDialectTranslate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		dialect       *dialect
+		err           error
+		wantDuplicate bool
+	}{
+		{
+			name:          "MySQL Duplicate",
+			dialect:       mysqlDialect,
+			err:           &mysql.MySQLError{Number: mysqlDuplicateEntry},
+			wantDuplicate: true,
+		},
+		{
+			name:    "MySQL Other",
+			dialect: mysqlDialect,
+			err:     &mysql.MySQLError{Number: 1146},
+		},
+		{
+			name:          "PostgreSQL Duplicate",
+			dialect:       postgresDialect,
+			err:           &pgconn.PgError{Code: postgresUniqueViolation},
+			wantDuplicate: true,
+		},
+		{
+			name:          "SQLite Duplicate",
+			dialect:       sqliteDialect,
+			err:           errors.New("constraint failed: UNIQUE constraint failed: users.username (2067)"),
+			wantDuplicate: true,
+		},
+		{
+			name:    "No Rows",
+			dialect: sqliteDialect,
+			err:     sql.ErrNoRows,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.dialect.translate(tc.err)
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.wantDuplicate, errors.Is(err, ErrDuplicate))
+		})
+	}
+
+	require.NoError(t, postgresDialect.translate(nil))
+}
@@ -0,0 +1,14 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+)
+
+// NewMySQLTestDB hands a fresh database of newTestDB to the external test
+// package.
+func NewMySQLTestDB(t *testing.T) *sql.DB {
+	return newTestDB(t)
+}
+
+var NewMigratorForConn = newMigrator
@@ -0,0 +1,199 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixtures are the YAML files in testdata/fixtures. Every key of a file is a
+// table holding the rows to insert, and the tables are filled in the order of
+// the file, so the rows other rows refer to have to come first:
+//
+//	users:
+//	  - id: 1
+//	    username: alice
+//	    hashed_password: secret
+//	Todo:
+//	  - id: 1
+//	    user_id: 1
+//	    title: Buy milk
+//	    create_date: 2024-05-01 09:00:00
+//
+// Columns that are left out get their default. Timestamps without a zone are
+// UTC.
+
+var fixtureIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type fixtureRow struct {
+	table   string
+	columns []string
+	values  []interface{}
+}
+
+// parseFixtures returns the rows of a fixture file in the order they are
+// inserted.
+func parseFixtures(data []byte) ([]fixtureRow, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	tables := doc.Content[0]
+	if tables.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: fixtures must map table names to rows", tables.Line)
+	}
+
+	var rows []fixtureRow
+	for i := 0; i < len(tables.Content); i += 2 {
+		table, items := tables.Content[i].Value, tables.Content[i+1]
+		if !fixtureIdentifier.MatchString(table) {
+			return nil, fmt.Errorf("line %d: invalid table name %q", tables.Content[i].Line, table)
+		}
+		if items.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("line %d: the rows of %s must be a list", items.Line, table)
+		}
+
+		for _, item := range items.Content {
+			row, err := parseFixtureRow(table, item)
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func parseFixtureRow(table string, item *yaml.Node) (fixtureRow, error) {
+	if item.Kind != yaml.MappingNode || len(item.Content) == 0 {
+		return fixtureRow{}, fmt.Errorf("line %d: a row of %s must map columns to values", item.Line, table)
+	}
+
+	row := fixtureRow{table: table}
+	for i := 0; i < len(item.Content); i += 2 {
+		column, node := item.Content[i].Value, item.Content[i+1]
+		if !fixtureIdentifier.MatchString(column) {
+			return fixtureRow{}, fmt.Errorf("line %d: invalid column name %q", item.Content[i].Line, column)
+		}
+		if node.Kind != yaml.ScalarNode {
+			return fixtureRow{}, fmt.Errorf("line %d: the value of %s.%s must be a scalar", node.Line, table, column)
+		}
+
+		// yaml.v3 leaves timestamps as strings unless asked for a time.Time.
+		var value interface{}
+		var err error
+		if node.ShortTag() == "!!timestamp" {
+			var t time.Time
+			err = node.Decode(&t)
+			value = t
+		} else {
+			err = node.Decode(&value)
+		}
+		if err != nil {
+			return fixtureRow{}, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+
+		row.columns = append(row.columns, column)
+		row.values = append(row.values, value)
+	}
+	return row, nil
+}
+
+func (row fixtureRow) insert() (string, []interface{}) {
+	columns := make([]string, len(row.columns))
+	placeholders := make([]string, len(row.columns))
+	for i, column := range row.columns {
+		columns[i] = "`" + column + "`"
+		placeholders[i] = "?"
+	}
+	query := "INSERT INTO `" + row.table + "` (" + strings.Join(columns, ", ") + ") VALUES (" + strings.Join(placeholders, ", ") + ")"
+	return query, row.values
+}
+
+// loadFixtures inserts the rows of testdata/fixtures/NAME.yml for each name
+// within a single transaction.
+func loadFixtures(t *testing.T, conn *sql.DB, names ...string) {
+	t.Helper()
+
+	tx, err := conn.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join("testdata", "fixtures", name+".yml"))
+		require.NoError(t, err)
+		rows, err := parseFixtures(data)
+		require.NoError(t, err, name)
+
+		for _, row := range rows {
+			query, args := row.insert()
+			_, err := tx.ExecContext(context.Background(), query, args...)
+			require.NoError(t, err, "%s: %s", name, query)
+		}
+	}
+	require.NoError(t, tx.Commit())
+}
+
+func TestParseFixtures(t *testing.T) {
+	data := []byte(`
+users:
+  - id: 1
+    username: alice
+Todo:
+  - id: 2
+    user_id: 1
+    title: Buy milk
+    due_at: 2024-05-01 09:30:00
+    deleted_at: null
+`)
+	rows, err := parseFixtures(data)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	query, args := rows[0].insert()
+	require.Equal(t, "INSERT INTO `users` (`id`, `username`) VALUES (?, ?)", query)
+	require.Equal(t, []interface{}{1, "alice"}, args)
+
+	query, args = rows[1].insert()
+	require.Equal(t, "INSERT INTO `Todo` (`id`, `user_id`, `title`, `due_at`, `deleted_at`) VALUES (?, ?, ?, ?, ?)", query)
+	require.Equal(t, []interface{}{2, 1, "Buy milk", time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC), nil}, args)
+
+	for _, invalid := range []string{
+		"- users",
+		"users: alice",
+		"users:\n  - alice",
+		"users:\n  - {}",
+		"users; DROP TABLE users:\n  - id: 1",
+		"users:\n  - id`: 1",
+		"users:\n  - id: [1, 2]",
+	} {
+		_, err := parseFixtures([]byte(invalid))
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestFixtureFiles(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "fixtures", "*.yml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		rows, err := parseFixtures(data)
+		require.NoError(t, err, file)
+		require.NotEmpty(t, rows, file)
+	}
+}
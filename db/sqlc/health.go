@@ -0,0 +1,27 @@
+package db
+
+import (
+	"context"
+)
+
+// SchemaVersion is the state of the schema_migrations table kept by migrate.
+// Dirty is set when a migration failed half way and needs fixing by hand.
+type SchemaVersion struct {
+	Version int64
+	Dirty   bool
+}
+
+const getSchemaVersion = `SELECT version, dirty FROM schema_migrations LIMIT 1`
+
+// GetSchemaVersion returns the version the database is migrated to.
+func (q *Queries) GetSchemaVersion(ctx context.Context) (SchemaVersion, error) {
+	row := q.db.QueryRowContext(ctx, namedQuery("GetSchemaVersion", getSchemaVersion))
+	var i SchemaVersion
+	err := row.Scan(&i.Version, &i.Dirty)
+	return i, err
+}
+
+// Ping checks that the database can be reached.
+func (repo *SQLRepo) Ping(ctx context.Context) error {
+	return repo.db.PingContext(ctx)
+}
@@ -0,0 +1,27 @@
+package db
+
+import (
+	"context"
+	"first-app/todo_go/db/migration"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPing(t *testing.T) {
+	repo := newTestRepo(t)
+	require.NoError(t, repo.Ping(context.Background()))
+}
+
+func TestGetSchemaVersion(t *testing.T) {
+	repo := newTestRepo(t)
+	migrations, err := migration.ForDriver("mysql")
+	require.NoError(t, err)
+	expected, err := LatestMigrationVersion(migrations)
+	require.NoError(t, err)
+
+	version, err := repo.GetSchemaVersion(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, expected, version.Version)
+	require.False(t, version.Dirty)
+}
@@ -1,37 +1,88 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"first-app/todo_go/util"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"testing"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
 )
 
-var testQueries *Queries
+// testConfig points at the MySQL server of app.env. Every test gets its own
+// database on that server, see newTestDB.
+var testConfig util.Config
+
+// testServer is a connection to the server without a default database, used
+// to create and drop the databases of the tests.
+var testServer *sql.DB
 
 func TestMain(m *testing.M) {
-	config, err := util.LoadConfig("../..")
+	var err error
+	testConfig, err = util.LoadConfig("../..")
 	if err != nil {
 		log.Fatal("cannot load config:", err)
 	}
 
-	dbSource := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/todo?parseTime=true&loc=Asia%%2FTokyo",
-		config.DBUser,
-		config.DBPassword,
-		config.DBHost,
-		config.DBPort,
-	)
-	testDB, err := sql.Open(config.DBDriver, dbSource)
+	serverConfig := testConfig
+	serverConfig.DBName = ""
+	testServer, err = NewDB(serverConfig)
 	if err != nil {
 		log.Fatal("Cannot connect to db: ", err)
 	}
 
-	testQueries = New(testDB)
+	code := m.Run()
+	testServer.Close()
+	os.Exit(code)
+}
+
+// createTestDatabase creates an empty database for the test and drops it when
+// the test ends. It returns the config of the new database.
+func createTestDatabase(t *testing.T) util.Config {
+	t.Helper()
+
+	config := testConfig
+	config.DBName = fmt.Sprintf("%s_test_%s", testConfig.DBName, strings.ToLower(util.RandomString(12)))
+
+	_, err := testServer.ExecContext(context.Background(), "CREATE DATABASE `"+config.DBName+"`")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := testServer.ExecContext(context.Background(), "DROP DATABASE IF EXISTS `"+config.DBName+"`")
+		require.NoError(t, err)
+	})
+
+	return config
+}
+
+// newTestDB returns a connection to a database of its own for the test,
+// migrated to the latest version. Tests never see each other's rows, so they
+// can run in any order and count rows without clearing tables first.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	config := createTestDatabase(t)
+
+	migrator, err := NewMigrator(config)
+	require.NoError(t, err)
+	defer migrator.Close()
+	require.NoError(t, migrator.Up())
+
+	conn, err := NewDB(config)
+	require.NoError(t, err)
+	// Registered after the drop, so it runs before it.
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// newTestRepo returns the repo of a fresh database, see newTestDB. Its
+// embedded Queries run the queries outside a transaction.
+func newTestRepo(t *testing.T) *SQLRepo {
+	t.Helper()
 
-	os.Exit(m.Run())
+	return newSQLRepo(mysqlDialect, newTestDB(t), nil)
 }
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"first-app/todo_go/db/migration"
+	"first-app/todo_go/util"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/mysql"
+	pgx "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// MigrationStatus tells if one migration has been applied.
+type MigrationStatus struct {
+	Version int64
+	Name    string
+	Applied bool
+}
+
+// Migrator runs the migrations embedded in the binary. The versions are kept
+// in the schema_migrations table, the same one the migrate CLI uses, and a
+// lock on the database stops two replicas from migrating at the same time.
+type Migrator struct {
+	conn   *sql.DB
+	source source.Driver
+	m      *migrate.Migrate
+}
+
+// NewMigrator connects to the database described by config for migrating
+// it with the migrations of its driver. The migrations hold several
+// statements each, so unlike NewDB the connection allows multi statement
+// queries.
+func NewMigrator(config util.Config) (*Migrator, error) {
+	b, err := backendFor(config.DBDriver)
+	if err != nil {
+		return nil, err
+	}
+	dsn, err := b.migrationDSN(config)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := sql.Open(b.driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := newMigrator(conn, config.DBDriver)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return migrator, nil
+}
+
+func newMigrator(conn *sql.DB, driver string) (*Migrator, error) {
+	b, err := backendFor(driver)
+	if err != nil {
+		return nil, err
+	}
+	fsys, err := migration.ForDriver(driver)
+	if err != nil {
+		return nil, err
+	}
+	src, err := iofs.New(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	dbDriver, err := b.migrationDriver(conn)
+	if err != nil {
+		return nil, err
+	}
+	m, err := migrate.NewWithInstance("iofs", src, driver, dbDriver)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{conn: conn, source: src, m: m}, nil
+}
+
+func mysqlMigrationDSN(config util.Config) (string, error) {
+	cfg, err := mysqlConfig(config)
+	if err != nil {
+		return "", err
+	}
+	cfg.MultiStatements = true
+	return cfg.FormatDSN(), nil
+}
+
+// mysqlMigrationDriver and postgresMigrationDriver take the database name
+// from the connection.
+func mysqlMigrationDriver(conn *sql.DB) (database.Driver, error) {
+	return mysql.WithInstance(conn, &mysql.Config{})
+}
+
+func postgresMigrationDriver(conn *sql.DB) (database.Driver, error) {
+	return pgx.WithInstance(conn, &pgx.Config{})
+}
+
+// Up applies every pending migration.
+func (migrator *Migrator) Up() error {
+	if err := migrator.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Down rolls back the last steps migrations.
+func (migrator *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("invalid number of steps %d", steps)
+	}
+	if err := migrator.m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Version returns the version the database is at, 0 before the first
+// migration.
+func (migrator *Migrator) Version() (SchemaVersion, error) {
+	version, dirty, err := migrator.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return SchemaVersion{}, nil
+	}
+	if err != nil {
+		return SchemaVersion{}, err
+	}
+	return SchemaVersion{Version: int64(version), Dirty: dirty}, nil
+}
+
+// Status lists every embedded migration and if it has been applied.
+func (migrator *Migrator) Status() ([]MigrationStatus, error) {
+	current, err := migrator.Version()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []MigrationStatus
+	version, err := migrator.source.First()
+	for err == nil {
+		body, name, readErr := migrator.source.ReadUp(version)
+		if readErr != nil {
+			return nil, readErr
+		}
+		body.Close()
+		items = append(items, MigrationStatus{
+			Version: int64(version),
+			Name:    name,
+			Applied: int64(version) <= current.Version,
+		})
+		version, err = migrator.source.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Close releases the source and the database connection.
+func (migrator *Migrator) Close() error {
+	srcErr, dbErr := migrator.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+// LatestMigrationVersion returns the highest version among the migration
+// files in fsys, named like 000001_init_schema.up.sql.
+func LatestMigrationVersion(fsys fs.FS) (int64, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".up.sql") {
+			continue
+		}
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return 0, fmt.Errorf("invalid migration file name %q", name)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file name %q: %w", name, err)
+		}
+		latest = max(latest, version)
+	}
+	if latest == 0 {
+		return 0, errors.New("no migrations found")
+	}
+	return latest, nil
+}
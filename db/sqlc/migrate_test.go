@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"first-app/todo_go/db/migration"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigratorStatus(t *testing.T) {
+	config := createTestDatabase(t)
+
+	migrator, err := NewMigrator(config)
+	require.NoError(t, err)
+	defer migrator.Close()
+
+	version, err := migrator.Version()
+	require.NoError(t, err)
+	require.Zero(t, version.Version)
+
+	require.NoError(t, migrator.Up())
+
+	migrations, err := migration.ForDriver(config.DBDriver)
+	require.NoError(t, err)
+	latest, err := LatestMigrationVersion(migrations)
+	require.NoError(t, err)
+
+	version, err = migrator.Version()
+	require.NoError(t, err)
+	require.Equal(t, SchemaVersion{Version: latest}, version)
+
+	status, err := migrator.Status()
+	require.NoError(t, err)
+	require.Len(t, status, int(latest))
+	require.Equal(t, MigrationStatus{Version: 1, Name: "init_schema", Applied: true}, status[0])
+	for _, item := range status {
+		require.True(t, item.Applied)
+	}
+}
+
+// TestMigrateLegacyTodos migrates a database with todos from before there
+// were accounts, see the add_users migration.
+func TestMigrateLegacyTodos(t *testing.T) {
+	config := createTestDatabase(t)
+
+	migrator, err := NewMigrator(config)
+	require.NoError(t, err)
+	defer migrator.Close()
+	migrations, err := migration.ForDriver(config.DBDriver)
+	require.NoError(t, err)
+	latest, err := LatestMigrationVersion(migrations)
+	require.NoError(t, err)
+
+	conn, err := NewDB(config)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, migrator.Up())
+	require.NoError(t, migrator.Down(int(latest)-1))
+	updated := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	_, err = conn.ExecContext(context.Background(),
+		"INSERT INTO `Todo` (`title`, `description`, `update_date`) VALUES (?, ?, ?)", "Old", "from before accounts", updated)
+	require.NoError(t, err)
+
+	require.NoError(t, migrator.Up())
+
+	var username string
+	var updateDate sql.NullTime
+	err = conn.QueryRowContext(context.Background(),
+		"SELECT u.username, t.update_date FROM `Todo` t JOIN `users` u ON u.id = t.user_id WHERE t.title = ?", "Old").
+		Scan(&username, &updateDate)
+	require.NoError(t, err)
+	require.Equal(t, "legacy", username)
+	require.True(t, updateDate.Time.Equal(updated), updateDate.Time)
+
+	_, err = conn.ExecContext(context.Background(),
+		"INSERT INTO `Todo` (`title`, `description`, `user_id`) VALUES (?, ?, ?)", "Orphan", "no such user", 999)
+	require.Error(t, err)
+
+	require.NoError(t, migrator.Down(int(latest)))
+}
+
+func TestLatestMigrationVersion(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   []string
+		want    int64
+		wantErr bool
+	}{
+		{
+			name:  "OK",
+			files: []string{"000001_init.up.sql", "000001_init.down.sql", "000012_tags.up.sql", "000003_users.up.sql"},
+			want:  12,
+		},
+		{
+			name:    "Empty",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid Name",
+			files:   []string{"init.up.sql"},
+			wantErr: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for _, name := range tc.files {
+				fsys[name] = &fstest.MapFile{}
+			}
+
+			version, err := LatestMigrationVersion(fsys)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, version)
+		})
+	}
+}
+
+func TestMigrationsForDriver(t *testing.T) {
+	names := func(driver string) []string {
+		fsys, err := migration.ForDriver(driver)
+		require.NoError(t, err)
+		entries, err := fs.ReadDir(fsys, ".")
+		require.NoError(t, err)
+
+		var files []string
+		for _, entry := range entries {
+			files = append(files, entry.Name())
+		}
+		return files
+	}
+
+	// Every driver has the same versions, so that readyz and the migrate
+	// subcommand mean the same schema whatever the database.
+	mysqlFiles := names("mysql")
+	require.NotEmpty(t, mysqlFiles)
+	require.Equal(t, mysqlFiles, names("postgres"))
+	require.Equal(t, mysqlFiles, names("sqlite"))
+
+	_, err := migration.ForDriver("oracle")
+	require.Error(t, err)
+}
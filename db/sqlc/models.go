@@ -6,12 +6,89 @@ package db
 
 import (
 	"database/sql"
+	"fmt"
 )
 
+type TodoPriority string
+
+const (
+	TodoPriorityLow    TodoPriority = "low"
+	TodoPriorityMedium TodoPriority = "medium"
+	TodoPriorityHigh   TodoPriority = "high"
+)
+
+func (e *TodoPriority) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TodoPriority(s)
+	case string:
+		*e = TodoPriority(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TodoPriority: %T", src)
+	}
+	return nil
+}
+
+type TodoStatus string
+
+const (
+	TodoStatusOpen       TodoStatus = "open"
+	TodoStatusInProgress TodoStatus = "in_progress"
+	TodoStatusDone       TodoStatus = "done"
+)
+
+func (e *TodoStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TodoStatus(s)
+	case string:
+		*e = TodoStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TodoStatus: %T", src)
+	}
+	return nil
+}
+
+type AccessToken struct {
+	ID         int64        `json:"id"`
+	UserID     int64        `json:"user_id"`
+	Name       string       `json:"name"`
+	TokenHash  string       `json:"token_hash"`
+	Scopes     string       `json:"scopes"`
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+	CreateDate sql.NullTime `json:"create_date"`
+}
+
+type Tag struct {
+	ID         int64        `json:"id"`
+	UserID     int64        `json:"user_id"`
+	Name       string       `json:"name"`
+	CreateDate sql.NullTime `json:"create_date"`
+}
+
 type Todo struct {
 	ID          int64          `json:"id"`
 	Title       sql.NullString `json:"title"`
 	Description sql.NullString `json:"description"`
 	CreateDate  sql.NullTime   `json:"create_date"`
 	UpdateDate  sql.NullTime   `json:"update_date"`
+	UserID      int64          `json:"user_id"`
+	Status      TodoStatus     `json:"status"`
+	Priority    TodoPriority   `json:"priority"`
+	DueAt       sql.NullTime   `json:"due_at"`
+	DeletedAt   sql.NullTime   `json:"deleted_at"`
+}
+
+type TodoTag struct {
+	TodoID int64 `json:"todo_id"`
+	TagID  int64 `json:"tag_id"`
+}
+
+type User struct {
+	ID             int64        `json:"id"`
+	Username       string       `json:"username"`
+	HashedPassword string       `json:"hashed_password"`
+	CreateDate     sql.NullTime `json:"create_date"`
 }
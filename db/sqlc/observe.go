@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+const queryNamePrefix = "-- name: "
+
+// QueryObserver is told about every query the repo runs: its sqlc name, how
+// long it took and the error it returned, if any.
+type QueryObserver func(name string, duration time.Duration, err error)
+
+// namedQuery prefixes a query built at runtime with a name comment like the
+// ones sqlc puts on the generated queries, so the observer can tell it apart.
+func namedQuery(name, query string) string {
+	return queryNamePrefix + name + "\n" + query
+}
+
+// queryName returns the name from the sqlc comment a query starts with.
+func queryName(query string) string {
+	rest, ok := strings.CutPrefix(query, queryNamePrefix)
+	if !ok {
+		return "unknown"
+	}
+	if i := strings.IndexAny(rest, " \n"); i >= 0 {
+		rest = rest[:i]
+	}
+	return rest
+}
+
+// observedDBTX reports the duration of every query run on db to observe. For
+// QueryContext it is the time until the first rows are ready.
+type observedDBTX struct {
+	db      DBTX
+	observe QueryObserver
+}
+
+func (o observedDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := o.db.ExecContext(ctx, query, args...)
+	o.observe(queryName(query), time.Since(start), err)
+	return result, err
+}
+
+func (o observedDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return o.db.PrepareContext(ctx, query)
+}
+
+func (o observedDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := o.db.QueryContext(ctx, query, args...)
+	o.observe(queryName(query), time.Since(start), err)
+	return rows, err
+}
+
+func (o observedDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := o.db.QueryRowContext(ctx, query, args...)
+	o.observe(queryName(query), time.Since(start), row.Err())
+	return row
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryName(t *testing.T) {
+	testCases := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "Generated",
+			query: getTodo,
+			want:  "GetTodo",
+		},
+		{
+			name:  "Built",
+			query: namedQuery("ListTodo", "SELECT id FROM Todo"),
+			want:  "ListTodo",
+		},
+		{
+			name:  "Unnamed",
+			query: "SELECT 1",
+			want:  "unknown",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, queryName(tc.query))
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package db
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"first-app/todo_go/util"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	// tlsConfigName is the name the TLS config with DB_TLS_CA is registered
+	// under in the mysql driver.
+	tlsConfigName = "todo-custom-ca"
+
+	firstRetryWait = time.Second
+	maxRetryWait   = 30 * time.Second
+)
+
+// DSN builds the data source name for the database described by config.
+func DSN(config util.Config) (string, error) {
+	b, err := backendFor(config.DBDriver)
+	if err != nil {
+		return "", err
+	}
+	return b.dsn(config)
+}
+
+func mysqlDSN(config util.Config) (string, error) {
+	cfg, err := mysqlConfig(config)
+	if err != nil {
+		return "", err
+	}
+	return cfg.FormatDSN(), nil
+}
+
+func mysqlConfig(config util.Config) (*mysql.Config, error) {
+	loc, err := time.LoadLocation(config.DBTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_TIMEZONE: %w", err)
+	}
+	tlsConfig, err := tlsConfigFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := mysql.NewConfig()
+	cfg.User = config.DBUser
+	cfg.Passwd = config.DBPassword
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(config.DBHost, config.DBPort)
+	cfg.DBName = config.DBName
+	cfg.ParseTime = true
+	cfg.Loc = loc
+	cfg.Timeout = config.DBConnectTimeout
+	cfg.TLSConfig = tlsConfig
+	return cfg, nil
+}
+
+// tlsConfigFor returns the TLS config name of the mysql driver for the
+// configured mode, registering the one for DB_TLS_CA when it is set.
+func tlsConfigFor(config util.Config) (string, error) {
+	switch config.DBTLSMode {
+	case "", "false":
+		return "false", nil
+	case "skip-verify", "preferred":
+		return config.DBTLSMode, nil
+	case "true":
+	default:
+		return "", fmt.Errorf("invalid DB_TLS_MODE %q, expected false, true, skip-verify or preferred", config.DBTLSMode)
+	}
+	if len(config.DBTLSCA) == 0 {
+		return "true", nil
+	}
+
+	pem, err := os.ReadFile(config.DBTLSCA)
+	if err != nil {
+		return "", fmt.Errorf("cannot read DB_TLS_CA: %w", err)
+	}
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(pem) {
+		return "", fmt.Errorf("no certificate found in DB_TLS_CA %s", config.DBTLSCA)
+	}
+	err = mysql.RegisterTLSConfig(tlsConfigName, &tls.Config{
+		RootCAs:    rootCAs,
+		ServerName: config.DBHost,
+		MinVersion: tls.VersionTLS12,
+	})
+	if err != nil {
+		return "", err
+	}
+	return tlsConfigName, nil
+}
+
+// postgresSSLModes maps DB_TLS_MODE to the sslmode of PostgreSQL.
+var postgresSSLModes = map[string]string{
+	"":            "disable",
+	"false":       "disable",
+	"true":        "verify-full",
+	"skip-verify": "require",
+	"preferred":   "prefer",
+}
+
+func postgresDSN(config util.Config) (string, error) {
+	sslMode, ok := postgresSSLModes[config.DBTLSMode]
+	if !ok {
+		return "", fmt.Errorf("invalid DB_TLS_MODE %q, expected false, true, skip-verify or preferred", config.DBTLSMode)
+	}
+	if _, err := time.LoadLocation(config.DBTimezone); err != nil {
+		return "", fmt.Errorf("invalid DB_TIMEZONE: %w", err)
+	}
+
+	query := url.Values{}
+	query.Set("sslmode", sslMode)
+	if sslMode == "verify-full" && len(config.DBTLSCA) > 0 {
+		query.Set("sslrootcert", config.DBTLSCA)
+	}
+	if config.DBConnectTimeout > 0 {
+		query.Set("connect_timeout", strconv.Itoa(int(math.Ceil(config.DBConnectTimeout.Seconds()))))
+	}
+	if len(config.DBTimezone) > 0 && config.DBTimezone != "Local" {
+		query.Set("timezone", config.DBTimezone)
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.DBUser, config.DBPassword),
+		Host:     net.JoinHostPort(config.DBHost, config.DBPort),
+		Path:     "/" + config.DBName,
+		RawQuery: query.Encode(),
+	}
+	return dsn.String(), nil
+}
+
+// sqliteDSN opens DB_NAME as the path of the database file. Transactions take
+// the write lock as they begin, so two of them never deadlock upgrading their
+// read locks, and a connection waits up to DB_CONNECT_TIMEOUT for the lock.
+func sqliteDSN(config util.Config) (string, error) {
+	if len(config.DBName) == 0 {
+		return "", errors.New("DB_NAME must be the path of the SQLite database file")
+	}
+
+	query := url.Values{}
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", config.DBConnectTimeout.Milliseconds()))
+	query.Set("_txlock", "immediate")
+	return "file:" + config.DBName + "?" + query.Encode(), nil
+}
+
+// NewDB returns the connection pool for the database described by config,
+// tuned with the pool settings. Like sql.Open, it does not connect yet.
+func NewDB(config util.Config) (*sql.DB, error) {
+	b, err := backendFor(config.DBDriver)
+	if err != nil {
+		return nil, err
+	}
+	dsn, err := b.dsn(config)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sql.Open(b.driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetMaxOpenConns(config.DBMaxOpenConns)
+	conn.SetMaxIdleConns(config.DBMaxIdleConns)
+	conn.SetConnMaxLifetime(config.DBConnMaxLifetime)
+	return conn, nil
+}
+
+// Connect returns the connection pool like NewDB once the database answers,
+// trying again DB_CONNECT_RETRIES times while it is starting up.
+func Connect(ctx context.Context, config util.Config) (*sql.DB, error) {
+	conn, err := NewDB(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := pingWithRetry(ctx, conn, config.DBConnectRetries, firstRetryWait); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+type pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// pingWithRetry pings db up to retries+1 times, doubling the wait between
+// two attempts from wait up to maxRetryWait.
+func pingWithRetry(ctx context.Context, db pinger, retries int, wait time.Duration) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = db.PingContext(ctx); err == nil {
+			return nil
+		}
+		if attempt >= retries || errors.Is(err, context.Canceled) {
+			return fmt.Errorf("cannot reach the database after %d attempts: %w", attempt+1, err)
+		}
+
+		slog.WarnContext(ctx, "Cannot reach the database, retrying",
+			slog.Int("attempt", attempt+1),
+			slog.Duration("wait", wait),
+			slog.Any("error", err),
+		)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("cannot reach the database: %w", ctx.Err())
+		case <-time.After(wait):
+		}
+		wait = min(2*wait, maxRetryWait)
+	}
+}
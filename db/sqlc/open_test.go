@@ -0,0 +1,327 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"first-app/todo_go/util"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDSN(t *testing.T) {
+	base := util.Config{
+		DBDriver:         "mysql",
+		DBUser:           "root",
+		DBPassword:       "p@ss",
+		DBHost:           "db.local",
+		DBPort:           "3306",
+		DBName:           "todo_test",
+		DBTimezone:       "UTC",
+		DBConnectTimeout: 3 * time.Second,
+	}
+
+	testCases := []struct {
+		name     string
+		config   func(config util.Config) util.Config
+		checkDSN func(t *testing.T, cfg *mysql.Config, err error)
+	}{
+		{
+			name: "OK",
+			config: func(config util.Config) util.Config {
+				return config
+			},
+			checkDSN: func(t *testing.T, cfg *mysql.Config, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "root", cfg.User)
+				require.Equal(t, "p@ss", cfg.Passwd)
+				require.Equal(t, "db.local:3306", cfg.Addr)
+				require.Equal(t, "todo_test", cfg.DBName)
+				require.Equal(t, time.UTC, cfg.Loc)
+				require.True(t, cfg.ParseTime)
+				require.Equal(t, 3*time.Second, cfg.Timeout)
+				require.Equal(t, "false", cfg.TLSConfig)
+			},
+		},
+		{
+			name: "Timezone",
+			config: func(config util.Config) util.Config {
+				config.DBTimezone = "Asia/Tokyo"
+				return config
+			},
+			checkDSN: func(t *testing.T, cfg *mysql.Config, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "Asia/Tokyo", cfg.Loc.String())
+			},
+		},
+		{
+			name: "Invalid Timezone",
+			config: func(config util.Config) util.Config {
+				config.DBTimezone = "Mars/Olympus"
+				return config
+			},
+			checkDSN: func(t *testing.T, cfg *mysql.Config, err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name: "Invalid Driver",
+			config: func(config util.Config) util.Config {
+				config.DBDriver = "oracle"
+				return config
+			},
+			checkDSN: func(t *testing.T, cfg *mysql.Config, err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name: "TLS Skip Verify",
+			config: func(config util.Config) util.Config {
+				config.DBTLSMode = "skip-verify"
+				return config
+			},
+			checkDSN: func(t *testing.T, cfg *mysql.Config, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "skip-verify", cfg.TLSConfig)
+			},
+		},
+		{
+			name: "TLS Invalid Mode",
+			config: func(config util.Config) util.Config {
+				config.DBTLSMode = "always"
+				return config
+			},
+			checkDSN: func(t *testing.T, cfg *mysql.Config, err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name: "TLS Missing CA",
+			config: func(config util.Config) util.Config {
+				config.DBTLSMode = "true"
+				config.DBTLSCA = filepath.Join(t.TempDir(), "missing.pem")
+				return config
+			},
+			checkDSN: func(t *testing.T, cfg *mysql.Config, err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name: "TLS Invalid CA",
+			config: func(config util.Config) util.Config {
+				path := filepath.Join(t.TempDir(), "ca.pem")
+				require.NoError(t, os.WriteFile(path, []byte("not a certificate"), 0o600))
+				config.DBTLSMode = "true"
+				config.DBTLSCA = path
+				return config
+			},
+			checkDSN: func(t *testing.T, cfg *mysql.Config, err error) {
+				require.Error(t, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			dsn, err := DSN(tc.config(base))
+			if err != nil {
+				tc.checkDSN(t, nil, err)
+				return
+			}
+			cfg, err := mysql.ParseDSN(dsn)
+			require.NoError(t, err)
+			tc.checkDSN(t, cfg, nil)
+		})
+	}
+}
+
+func TestBuildPostgresDSN(t *testing.T) {
+	base := util.Config{
+		DBDriver:         "postgres",
+		DBUser:           "root",
+		DBPassword:       "p@ss",
+		DBHost:           "db.local",
+		DBPort:           "5432",
+		DBName:           "todo_test",
+		DBTimezone:       "Asia/Tokyo",
+		DBConnectTimeout: 2500 * time.Millisecond,
+	}
+
+	testCases := []struct {
+		name     string
+		config   func(config util.Config) util.Config
+		checkDSN func(t *testing.T, dsn *url.URL, err error)
+	}{
+		{
+			name: "OK",
+			config: func(config util.Config) util.Config {
+				return config
+			},
+			checkDSN: func(t *testing.T, dsn *url.URL, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "postgres", dsn.Scheme)
+				require.Equal(t, "root", dsn.User.Username())
+				password, _ := dsn.User.Password()
+				require.Equal(t, "p@ss", password)
+				require.Equal(t, "db.local:5432", dsn.Host)
+				require.Equal(t, "/todo_test", dsn.Path)
+				require.Equal(t, "disable", dsn.Query().Get("sslmode"))
+				require.Equal(t, "3", dsn.Query().Get("connect_timeout"))
+				require.Equal(t, "Asia/Tokyo", dsn.Query().Get("timezone"))
+			},
+		},
+		{
+			name: "TLS With CA",
+			config: func(config util.Config) util.Config {
+				config.DBTLSMode = "true"
+				config.DBTLSCA = "/etc/ssl/db-ca.pem"
+				return config
+			},
+			checkDSN: func(t *testing.T, dsn *url.URL, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "verify-full", dsn.Query().Get("sslmode"))
+				require.Equal(t, "/etc/ssl/db-ca.pem", dsn.Query().Get("sslrootcert"))
+			},
+		},
+		{
+			name: "TLS Skip Verify",
+			config: func(config util.Config) util.Config {
+				config.DBTLSMode = "skip-verify"
+				return config
+			},
+			checkDSN: func(t *testing.T, dsn *url.URL, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "require", dsn.Query().Get("sslmode"))
+			},
+		},
+		{
+			name: "TLS Invalid Mode",
+			config: func(config util.Config) util.Config {
+				config.DBTLSMode = "always"
+				return config
+			},
+			checkDSN: func(t *testing.T, dsn *url.URL, err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name: "Invalid Timezone",
+			config: func(config util.Config) util.Config {
+				config.DBTimezone = "Mars/Olympus"
+				return config
+			},
+			checkDSN: func(t *testing.T, dsn *url.URL, err error) {
+				require.Error(t, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			dsn, err := postgresDSN(tc.config(base))
+			if err != nil {
+				tc.checkDSN(t, nil, err)
+				return
+			}
+			parsed, err := url.Parse(dsn)
+			require.NoError(t, err)
+			tc.checkDSN(t, parsed, nil)
+		})
+	}
+}
+
+func TestBuildSQLiteDSN(t *testing.T) {
+	dsn, err := sqliteDSN(util.Config{DBName: "todo.db", DBConnectTimeout: 5 * time.Second})
+	require.NoError(t, err)
+
+	path, rawQuery, ok := strings.Cut(dsn, "?")
+	require.True(t, ok)
+	require.Equal(t, "file:todo.db", path)
+	query, err := url.ParseQuery(rawQuery)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"foreign_keys(1)", "journal_mode(WAL)", "busy_timeout(5000)"}, query["_pragma"])
+	require.Equal(t, "immediate", query.Get("_txlock"))
+
+	_, err = sqliteDSN(util.Config{})
+	require.Error(t, err)
+}
+
+type fakePinger struct {
+	errs  []error
+	calls int
+}
+
+func (p *fakePinger) PingContext(ctx context.Context) error {
+	p.calls++
+	if len(p.errs) == 0 {
+		return nil
+	}
+	err := p.errs[0]
+	p.errs = p.errs[1:]
+	return err
+}
+
+func TestPingWithRetry(t *testing.T) {
+	errDown := errors.New("connection refused")
+
+	testCases := []struct {
+		name      string
+		errs      []error
+		retries   int
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "OK",
+			wantCalls: 1,
+		},
+		{
+			name:      "Up After Retries",
+			errs:      []error{errDown, errDown},
+			retries:   2,
+			wantCalls: 3,
+		},
+		{
+			name:      "Gives Up",
+			errs:      []error{errDown, errDown, errDown},
+			retries:   1,
+			wantCalls: 2,
+			wantErr:   true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			db := &fakePinger{errs: tc.errs}
+			err := pingWithRetry(context.Background(), db, tc.retries, time.Millisecond)
+			require.Equal(t, tc.wantCalls, db.calls)
+			if tc.wantErr {
+				require.ErrorIs(t, err, errDown)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("Stops with context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		db := &fakePinger{errs: []error{errDown}}
+		err := pingWithRetry(ctx, db, 5, time.Hour)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 1, db.calls)
+	})
+}
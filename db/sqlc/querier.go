@@ -10,14 +10,33 @@ import (
 )
 
 type Querier interface {
-	ClearTodo(ctx context.Context) error
-	CountTodo(ctx context.Context) (int64, error)
+	AttachTag(ctx context.Context, arg AttachTagParams) error
+	ClearTodo(ctx context.Context, userID int64) error
+	CountDeletedTodo(ctx context.Context, userID int64) (int64, error)
+	CreateAccessToken(ctx context.Context, arg CreateAccessTokenParams) (sql.Result, error)
+	CreateTag(ctx context.Context, arg CreateTagParams) (sql.Result, error)
 	CreateTodo(ctx context.Context, arg CreateTodoParams) (sql.Result, error)
-	DeleteTodo(ctx context.Context, id int64) error
-	DeleteTodoList(ctx context.Context, ids string) error
-	GetTodo(ctx context.Context, id int64) (Todo, error)
-	ListTodo(ctx context.Context, arg ListTodoParams) ([]Todo, error)
+	CreateUser(ctx context.Context, arg CreateUserParams) (sql.Result, error)
+	DeleteTodo(ctx context.Context, arg DeleteTodoParams) error
+	DetachAllTags(ctx context.Context, todoID int64) error
+	DetachTag(ctx context.Context, arg DetachTagParams) error
+	GetAccessTokenByHash(ctx context.Context, tokenHash string) (AccessToken, error)
+	GetTagByName(ctx context.Context, arg GetTagByNameParams) (Tag, error)
+	GetTodo(ctx context.Context, arg GetTodoParams) (Todo, error)
+	GetUser(ctx context.Context, id int64) (User, error)
+	GetUserByUsername(ctx context.Context, username string) (User, error)
+	ListAccessTokens(ctx context.Context, userID int64) ([]AccessToken, error)
+	ListDeletedTodo(ctx context.Context, arg ListDeletedTodoParams) ([]Todo, error)
+	ListTags(ctx context.Context, userID int64) ([]Tag, error)
+	ListTagsByTodo(ctx context.Context, arg ListTagsByTodoParams) ([]Tag, error)
+	PurgeDeletedTodo(ctx context.Context, retentionSeconds int64) (int64, error)
+	PurgeTodo(ctx context.Context, arg PurgeTodoParams) (int64, error)
+	RestoreTodo(ctx context.Context, arg RestoreTodoParams) (int64, error)
+	RevokeAccessToken(ctx context.Context, arg RevokeAccessTokenParams) error
+	ToggleTodo(ctx context.Context, arg ToggleTodoParams) error
+	TouchAccessToken(ctx context.Context, id int64) error
 	UpdateTodo(ctx context.Context, arg UpdateTodoParams) error
+	UpsertTag(ctx context.Context, arg UpsertTagParams) (sql.Result, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -1,39 +1,87 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 )
 
 type Repo interface {
 	Querier
+	CountTodo(ctx context.Context, arg TodoFilter) (int64, error)
+	ListTodo(ctx context.Context, arg ListTodoParams) ([]Todo, error)
+	ListTagsByTodoIDs(ctx context.Context, arg ListTagsByTodoIDsParams) (map[int64][]Tag, error)
+	CreateTodoTx(ctx context.Context, arg CreateTodoTxParams) (CreateTodoTxResult, error)
+	ReplaceTodoTagsTx(ctx context.Context, arg ReplaceTodoTagsTxParams) ([]Tag, error)
+	DeleteTodoListTx(ctx context.Context, arg DeleteTodoListParams) (DeleteTodoListTxResult, error)
+	GetSchemaVersion(ctx context.Context) (SchemaVersion, error)
+	Ping(ctx context.Context) error
 }
 
 type SQLRepo struct {
 	*Queries
-	db *sql.DB
+	db      *sql.DB
+	dialect *dialect
+	observe QueryObserver
 }
 
+// NewRepo returns the repo of a MySQL database.
 func NewRepo(db *sql.DB) Repo {
-	return &SQLRepo{
+	return NewObservedRepo(db, nil)
+}
+
+// NewObservedRepo returns the repo of a MySQL database that reports every
+// query, including the ones run in transactions, to observe. A nil observe
+// reports nothing.
+func NewObservedRepo(db *sql.DB, observe QueryObserver) Repo {
+	return newSQLRepo(mysqlDialect, db, observe)
+}
+
+// NewDriverRepo is NewObservedRepo for a database of the DB_DRIVER driver,
+// like the ones NewDB opens.
+func NewDriverRepo(driver string, db *sql.DB, observe QueryObserver) (Repo, error) {
+	b, err := backendFor(driver)
+	if err != nil {
+		return nil, err
+	}
+	return newSQLRepo(b.dialect, db, observe), nil
+}
+
+func newSQLRepo(dialect *dialect, db *sql.DB, observe QueryObserver) *SQLRepo {
+	repo := &SQLRepo{
 		db:      db,
-		Queries: New(db),
+		dialect: dialect,
+		observe: observe,
 	}
+	repo.Queries = New(repo.dbtx(db))
+	return repo
 }
 
-// execTx executes a function within a database transaction
-// func (repo *SQLRepo) execTx(ctx context.Context, fn func(*Queries) error) error {
-// 	tx, err := repo.db.BeginTx(ctx, nil)
-// 	if err != nil {
-// 		return err
-// 	}
+// dbtx wraps db so that its queries are rewritten for the repo dialect and
+// reported to the repo observer under their sqlc name.
+func (repo *SQLRepo) dbtx(db DBTX) DBTX {
+	db = dialectDBTX{db: db, dialect: repo.dialect}
+	if repo.observe == nil {
+		return db
+	}
+	return observedDBTX{db: db, observe: repo.observe}
+}
 
-// 	q := New(tx)
-// 	err = fn(q)
-// 	if err != nil {
-// 		if rbErr := tx.Rollback(); rbErr != nil {
-// 			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
-// 		}
+// execTx executes a function within a database transaction. The transaction
+// is rolled back when fn returns an error and committed otherwise.
+func (repo *SQLRepo) execTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
 
-// 	}
-// 	return tx.Commit()
-// }
+	q := New(repo.dbtx(tx))
+	err = fn(q)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,28 @@
+//go:build sqlite
+
+package db
+
+import (
+	"database/sql"
+
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	_ "modernc.org/sqlite"
+)
+
+// The pure Go SQLite driver adds several megabytes to the binary and a long
+// first compile, so it is only built in with the sqlite tag, for running the
+// app locally without a database server.
+func init() {
+	backends["sqlite"] = backend{
+		driverName:      "sqlite",
+		dialect:         sqliteDialect,
+		dsn:             sqliteDSN,
+		migrationDSN:    sqliteDSN,
+		migrationDriver: sqliteMigrationDriver,
+	}
+}
+
+func sqliteMigrationDriver(conn *sql.DB) (database.Driver, error) {
+	return sqlite.WithInstance(conn, &sqlite.Config{})
+}
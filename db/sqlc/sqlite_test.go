@@ -0,0 +1,88 @@
+//go:build sqlite
+
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"first-app/todo_go/db/migration"
+	"first-app/todo_go/db/repotest"
+	db "first-app/todo_go/db/sqlc"
+	"first-app/todo_go/util"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteConformance(t *testing.T) {
+	config := util.Config{
+		DBDriver:         "sqlite",
+		DBName:           filepath.Join(t.TempDir(), "todo.db"),
+		DBConnectTimeout: 5 * time.Second,
+		DBMaxOpenConns:   4,
+		DBMaxIdleConns:   4,
+	}
+
+	migrator, err := db.NewMigrator(config)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up())
+	require.NoError(t, migrator.Close())
+
+	conn, err := db.NewDB(config)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	repo, err := db.NewDriverRepo(config.DBDriver, conn, nil)
+	require.NoError(t, err)
+	repotest.Run(t, repo)
+}
+
+// TestSQLiteLegacyTodos migrates a database with todos from before there were
+// accounts, see the add_users migration.
+func TestSQLiteLegacyTodos(t *testing.T) {
+	config := util.Config{
+		DBDriver:         "sqlite",
+		DBName:           filepath.Join(t.TempDir(), "todo.db"),
+		DBConnectTimeout: 5 * time.Second,
+		DBMaxOpenConns:   1,
+		DBMaxIdleConns:   1,
+	}
+
+	migrator, err := db.NewMigrator(config)
+	require.NoError(t, err)
+	defer migrator.Close()
+	migrations, err := migration.ForDriver(config.DBDriver)
+	require.NoError(t, err)
+	latest, err := db.LatestMigrationVersion(migrations)
+	require.NoError(t, err)
+
+	conn, err := db.NewDB(config)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, migrator.Up())
+	require.NoError(t, migrator.Down(int(latest)-1))
+	updated := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	_, err = conn.ExecContext(context.Background(),
+		"INSERT INTO Todo (title, description, update_date) VALUES (?, ?, ?)", "Old", "from before accounts", updated)
+	require.NoError(t, err)
+
+	require.NoError(t, migrator.Up())
+
+	var username string
+	var updateDate sql.NullTime
+	err = conn.QueryRowContext(context.Background(),
+		"SELECT u.username, t.update_date FROM Todo t JOIN users u ON u.id = t.user_id WHERE t.title = ?", "Old").
+		Scan(&username, &updateDate)
+	require.NoError(t, err)
+	require.Equal(t, "legacy", username)
+	require.True(t, updateDate.Time.Equal(updated), updateDate.Time)
+
+	_, err = conn.ExecContext(context.Background(),
+		"INSERT INTO Todo (title, description, user_id) VALUES (?, ?, ?)", "Orphan", "no such user", 999)
+	require.Error(t, err)
+
+	require.NoError(t, migrator.Down(int(latest)))
+}
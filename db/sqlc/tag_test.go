@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomTag(t *testing.T, q Querier, user User, name string) Tag {
+	result, err := q.CreateTag(context.Background(), CreateTagParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	require.NoError(t, err)
+	id, _ := result.LastInsertId()
+
+	tag, err := q.GetTagByName(context.Background(), GetTagByNameParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	require.NoError(t, err)
+	require.Equal(t, id, tag.ID)
+
+	return tag
+}
+
+func TestCreateTag(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	createRandomTag(t, repo, user, "work")
+
+	// Names are unique per user only.
+	_, err := repo.CreateTag(context.Background(), CreateTagParams{UserID: user.ID, Name: "work"})
+	require.Error(t, err)
+
+	other := createRandomUser(t, repo)
+	createRandomTag(t, repo, other, "work")
+}
+
+func TestAttachAndDetachTag(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	todoId := createRandomTodo(t, repo, user)
+	work := createRandomTag(t, repo, user, "work")
+	home := createRandomTag(t, repo, user, "home")
+
+	for _, tag := range []Tag{work, home, work} {
+		err := repo.AttachTag(context.Background(), AttachTagParams{TodoID: todoId, TagID: tag.ID})
+		require.NoError(t, err)
+	}
+
+	tags, err := repo.ListTagsByTodo(context.Background(), ListTagsByTodoParams{TodoID: todoId, UserID: user.ID})
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+	require.Equal(t, "home", tags[0].Name)
+	require.Equal(t, "work", tags[1].Name)
+
+	// Another user cannot detach the tag.
+	other := createRandomUser(t, repo)
+	err = repo.DetachTag(context.Background(), DetachTagParams{TodoID: todoId, TagID: work.ID, UserID: other.ID})
+	require.NoError(t, err)
+
+	err = repo.DetachTag(context.Background(), DetachTagParams{TodoID: todoId, TagID: work.ID, UserID: user.ID})
+	require.NoError(t, err)
+
+	tags, err = repo.ListTagsByTodo(context.Background(), ListTagsByTodoParams{TodoID: todoId, UserID: user.ID})
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	require.Equal(t, home.ID, tags[0].ID)
+}
+
+func TestListTagsByTodoIDs(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	tagged := createRandomTodo(t, repo, user)
+	untagged := createRandomTodo(t, repo, user)
+	tag := createRandomTag(t, repo, user, "work")
+
+	err := repo.AttachTag(context.Background(), AttachTagParams{TodoID: tagged, TagID: tag.ID})
+	require.NoError(t, err)
+
+	items, err := repo.ListTagsByTodoIDs(context.Background(), ListTagsByTodoIDsParams{
+		UserID:  user.ID,
+		TodoIDs: []int64{tagged, untagged},
+	})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, []Tag{tag}, items[tagged])
+
+	items, err = repo.ListTagsByTodoIDs(context.Background(), ListTagsByTodoIDsParams{UserID: user.ID})
+	require.NoError(t, err)
+	require.Empty(t, items)
+}
+
+func TestCountTodoByTag(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	tagged := createRandomTodo(t, repo, user)
+	createRandomTodo(t, repo, user)
+	tag := createRandomTag(t, repo, user, "work")
+
+	err := repo.AttachTag(context.Background(), AttachTagParams{TodoID: tagged, TagID: tag.ID})
+	require.NoError(t, err)
+
+	count, err := repo.CountTodo(context.Background(), TodoFilter{UserID: user.ID, Tag: "work"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	count, err = repo.CountTodo(context.Background(), TodoFilter{UserID: user.ID, Tag: "missing"})
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	_, err = repo.GetTagByName(context.Background(), GetTagByNameParams{UserID: user.ID, Name: "missing"})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
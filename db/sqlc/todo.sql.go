@@ -11,20 +11,22 @@ import (
 )
 
 const clearTodo = `-- name: ClearTodo :exec
-TRUNCATE TABLE Todo
+DELETE FROM Todo
+WHERE user_id = ?
 `
 
-func (q *Queries) ClearTodo(ctx context.Context) error {
-	_, err := q.db.ExecContext(ctx, clearTodo)
+func (q *Queries) ClearTodo(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, clearTodo, userID)
 	return err
 }
 
-const countTodo = `-- name: CountTodo :one
+const countDeletedTodo = `-- name: CountDeletedTodo :one
 SELECT count(*) FROM Todo
+WHERE user_id = ? AND deleted_at IS NOT NULL
 `
 
-func (q *Queries) CountTodo(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countTodo)
+func (q *Queries) CountDeletedTodo(ctx context.Context, userID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDeletedTodo, userID)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -32,48 +34,57 @@ func (q *Queries) CountTodo(ctx context.Context) (int64, error) {
 
 const createTodo = `-- name: CreateTodo :execresult
 INSERT INTO Todo (
-  title, description
+  user_id, title, description, priority, due_at
 ) VALUES (
-  ?, ?
+  ?, ?, ?, ?, ?
 )
 `
 
 type CreateTodoParams struct {
+	UserID      int64          `json:"user_id"`
 	Title       sql.NullString `json:"title"`
 	Description sql.NullString `json:"description"`
+	Priority    TodoPriority   `json:"priority"`
+	DueAt       sql.NullTime   `json:"due_at"`
 }
 
 func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) (sql.Result, error) {
-	return q.db.ExecContext(ctx, createTodo, arg.Title, arg.Description)
+	return q.db.ExecContext(ctx, createTodo,
+		arg.UserID,
+		arg.Title,
+		arg.Description,
+		arg.Priority,
+		arg.DueAt,
+	)
 }
 
 const deleteTodo = `-- name: DeleteTodo :exec
-DELETE FROM Todo
-WHERE id = ?
+UPDATE Todo SET deleted_at = CURRENT_TIMESTAMP, update_date = update_date
+WHERE id = ? AND user_id = ? AND deleted_at IS NULL
 `
 
-func (q *Queries) DeleteTodo(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteTodo, id)
-	return err
+type DeleteTodoParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
 }
 
-const deleteTodoList = `-- name: DeleteTodoList :exec
-DELETE FROM Todo
-WHERE FIND_IN_SET(id, ?)
-`
-
-func (q *Queries) DeleteTodoList(ctx context.Context, ids string) error {
-	_, err := q.db.ExecContext(ctx, deleteTodoList, ids)
+func (q *Queries) DeleteTodo(ctx context.Context, arg DeleteTodoParams) error {
+	_, err := q.db.ExecContext(ctx, deleteTodo, arg.ID, arg.UserID)
 	return err
 }
 
 const getTodo = `-- name: GetTodo :one
-SELECT id, title, description, create_date, update_date FROM Todo
-WHERE id = ? LIMIT 1
+SELECT id, title, description, create_date, update_date, user_id, status, priority, due_at, deleted_at FROM Todo
+WHERE id = ? AND user_id = ? AND deleted_at IS NULL LIMIT 1
 `
 
-func (q *Queries) GetTodo(ctx context.Context, id int64) (Todo, error) {
-	row := q.db.QueryRowContext(ctx, getTodo, id)
+type GetTodoParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) GetTodo(ctx context.Context, arg GetTodoParams) (Todo, error) {
+	row := q.db.QueryRowContext(ctx, getTodo, arg.ID, arg.UserID)
 	var i Todo
 	err := row.Scan(
 		&i.ID,
@@ -81,23 +92,30 @@ func (q *Queries) GetTodo(ctx context.Context, id int64) (Todo, error) {
 		&i.Description,
 		&i.CreateDate,
 		&i.UpdateDate,
+		&i.UserID,
+		&i.Status,
+		&i.Priority,
+		&i.DueAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
-const listTodo = `-- name: ListTodo :many
-SELECT id, title, description, create_date, update_date FROM Todo
-ORDER BY id
+const listDeletedTodo = `-- name: ListDeletedTodo :many
+SELECT id, title, description, create_date, update_date, user_id, status, priority, due_at, deleted_at FROM Todo
+WHERE user_id = ? AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC
 LIMIT ?, ?
 `
 
-type ListTodoParams struct {
+type ListDeletedTodoParams struct {
+	UserID int64 `json:"user_id"`
 	Offset int32 `json:"offset"`
 	Limit  int32 `json:"limit"`
 }
 
-func (q *Queries) ListTodo(ctx context.Context, arg ListTodoParams) ([]Todo, error) {
-	rows, err := q.db.QueryContext(ctx, listTodo, arg.Offset, arg.Limit)
+func (q *Queries) ListDeletedTodo(ctx context.Context, arg ListDeletedTodoParams) ([]Todo, error) {
+	rows, err := q.db.QueryContext(ctx, listDeletedTodo, arg.UserID, arg.Offset, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -111,6 +129,11 @@ func (q *Queries) ListTodo(ctx context.Context, arg ListTodoParams) ([]Todo, err
 			&i.Description,
 			&i.CreateDate,
 			&i.UpdateDate,
+			&i.UserID,
+			&i.Status,
+			&i.Priority,
+			&i.DueAt,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -125,17 +148,92 @@ func (q *Queries) ListTodo(ctx context.Context, arg ListTodoParams) ([]Todo, err
 	return items, nil
 }
 
+const purgeDeletedTodo = `-- name: PurgeDeletedTodo :execrows
+DELETE FROM Todo
+WHERE deleted_at IS NOT NULL AND deleted_at < CURRENT_TIMESTAMP - INTERVAL ? SECOND
+`
+
+func (q *Queries) PurgeDeletedTodo(ctx context.Context, retentionSeconds int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedTodo, retentionSeconds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const purgeTodo = `-- name: PurgeTodo :execrows
+DELETE FROM Todo
+WHERE id = ? AND user_id = ? AND deleted_at IS NOT NULL
+`
+
+type PurgeTodoParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) PurgeTodo(ctx context.Context, arg PurgeTodoParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeTodo, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreTodo = `-- name: RestoreTodo :execrows
+UPDATE Todo SET deleted_at = NULL, update_date = update_date
+WHERE id = ? AND user_id = ? AND deleted_at IS NOT NULL
+`
+
+type RestoreTodoParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) RestoreTodo(ctx context.Context, arg RestoreTodoParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restoreTodo, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const toggleTodo = `-- name: ToggleTodo :exec
+UPDATE Todo SET status = CASE WHEN status = 'done' THEN 'open' ELSE 'done' END
+WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+`
+
+type ToggleTodoParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) ToggleTodo(ctx context.Context, arg ToggleTodoParams) error {
+	_, err := q.db.ExecContext(ctx, toggleTodo, arg.ID, arg.UserID)
+	return err
+}
+
 const updateTodo = `-- name: UpdateTodo :exec
-UPDATE Todo SET description = ?
-WHERE id = ?
+UPDATE Todo SET description = ?, status = ?, priority = ?, due_at = ?
+WHERE id = ? AND user_id = ? AND deleted_at IS NULL
 `
 
 type UpdateTodoParams struct {
 	Description sql.NullString `json:"description"`
+	Status      TodoStatus     `json:"status"`
+	Priority    TodoPriority   `json:"priority"`
+	DueAt       sql.NullTime   `json:"due_at"`
 	ID          int64          `json:"id"`
+	UserID      int64          `json:"user_id"`
 }
 
 func (q *Queries) UpdateTodo(ctx context.Context, arg UpdateTodoParams) error {
-	_, err := q.db.ExecContext(ctx, updateTodo, arg.Description, arg.ID)
+	_, err := q.db.ExecContext(ctx, updateTodo,
+		arg.Description,
+		arg.Status,
+		arg.Priority,
+		arg.DueAt,
+		arg.ID,
+		arg.UserID,
+	)
 	return err
 }
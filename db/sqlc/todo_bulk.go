@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+)
+
+// DeleteTodoList and lockTodoList are written by hand since sqlc cannot
+// expand a slice into an IN list for MySQL. The IN list lets MySQL look the
+// rows up by primary key.
+
+type DeleteTodoListParams struct {
+	UserID int64   `json:"user_id"`
+	IDs    []int64 `json:"ids"`
+}
+
+func buildLockTodoList(arg DeleteTodoListParams) (string, []interface{}) {
+	in, ids := inList(arg.IDs)
+	query := "SELECT id FROM Todo WHERE user_id = ? AND deleted_at IS NULL AND id IN " + in + " FOR UPDATE"
+	return query, append([]interface{}{arg.UserID}, ids...)
+}
+
+func buildDeleteTodoList(arg DeleteTodoListParams) (string, []interface{}) {
+	in, ids := inList(arg.IDs)
+	query := "UPDATE Todo SET deleted_at = CURRENT_TIMESTAMP, update_date = update_date WHERE user_id = ? AND deleted_at IS NULL AND id IN " + in
+	return query, append([]interface{}{arg.UserID}, ids...)
+}
+
+// lockTodoList returns the IDs of the listed todos that belong to the user and
+// are not in the trash yet, locking the rows until the transaction ends.
+func (q *Queries) lockTodoList(ctx context.Context, arg DeleteTodoListParams) ([]int64, error) {
+	query, args := buildLockTodoList(arg)
+	rows, err := q.db.QueryContext(ctx, namedQuery("LockTodoList", query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// DeleteTodoList moves the listed todos of the user to the trash and returns
+// the number of rows it changed.
+func (q *Queries) DeleteTodoList(ctx context.Context, arg DeleteTodoListParams) (int64, error) {
+	if len(arg.IDs) == 0 {
+		return 0, nil
+	}
+
+	query, args := buildDeleteTodoList(arg)
+	result, err := q.db.ExecContext(ctx, namedQuery("DeleteTodoList", query), args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteTodoListTxResult is the result of DeleteTodoListTx.
+type DeleteTodoListTxResult struct {
+	Deleted  int64   `json:"deleted"`
+	NotFound []int64 `json:"not_found"`
+}
+
+// DeleteTodoListTx moves the listed todos to the trash within a single
+// database transaction. IDs that do not belong to the user or are already in
+// the trash are reported back in NotFound.
+func (repo *SQLRepo) DeleteTodoListTx(ctx context.Context, arg DeleteTodoListParams) (DeleteTodoListTxResult, error) {
+	result := DeleteTodoListTxResult{NotFound: []int64{}}
+	if len(arg.IDs) == 0 {
+		return result, nil
+	}
+
+	err := repo.execTx(ctx, func(q *Queries) error {
+		found, err := q.lockTodoList(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		exists := make(map[int64]bool, len(found))
+		for _, id := range found {
+			exists[id] = true
+		}
+		result.NotFound = []int64{}
+		for _, id := range arg.IDs {
+			if !exists[id] {
+				result.NotFound = append(result.NotFound, id)
+			}
+		}
+
+		result.Deleted, err = q.DeleteTodoList(ctx, arg)
+		return err
+	})
+
+	return result, err
+}
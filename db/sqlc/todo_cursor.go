@@ -0,0 +1,219 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Keyset pagination reads a page as the todos that follow a cursor in the
+// sort order, instead of skipping OFFSET rows and counting the whole list. It
+// costs the same on the last page as on the first, and the pages do not
+// shift when todos are added or removed in between.
+
+// ErrInvalidCursor is returned for cursors that cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// TodoCursor marks a todo in a sorted list by its sort key and id. The page
+// of a cursor holds the todos after that todo, or the ones before it when
+// Before is set, so the todo itself is never part of it.
+type TodoCursor struct {
+	SortBy    TodoSortField
+	SortOrder SortOrder
+	ID        int64
+	// Title and Date hold the sort key of the title and date sorts.
+	Title  sql.NullString
+	Date   sql.NullTime
+	Before bool
+}
+
+// cursorData is the JSON of an encoded cursor, kept short since it travels
+// in URLs.
+type cursorData struct {
+	SortBy    TodoSortField `json:"s"`
+	SortOrder SortOrder     `json:"o"`
+	ID        int64         `json:"i"`
+	Title     *string       `json:"t,omitempty"`
+	Date      *time.Time    `json:"d,omitempty"`
+	Before    bool          `json:"b,omitempty"`
+}
+
+// sortKey returns the whitelisted sort field and order, falling back to id
+// ascending like ListTodo does.
+func sortKey(sortBy TodoSortField, order SortOrder) (TodoSortField, SortOrder) {
+	if _, ok := todoSortColumns[sortBy]; !ok {
+		sortBy = TodoSortID
+	}
+	if _, ok := sortOrderKeywords[order]; !ok {
+		order = SortAsc
+	}
+	return sortBy, order
+}
+
+func reverseOrder(order SortOrder) SortOrder {
+	if order == SortDesc {
+		return SortAsc
+	}
+	return SortDesc
+}
+
+// NewTodoCursor returns the cursor of the todo in a list sorted by sortBy and
+// order, for the page after it or, with before, the page before it.
+func NewTodoCursor(todo Todo, sortBy TodoSortField, order SortOrder, before bool) TodoCursor {
+	cursor := TodoCursor{ID: todo.ID, Before: before}
+	cursor.SortBy, cursor.SortOrder = sortKey(sortBy, order)
+	switch cursor.SortBy {
+	case TodoSortTitle:
+		cursor.Title = todo.Title
+	case TodoSortCreateDate:
+		cursor.Date = todo.CreateDate
+	case TodoSortUpdateDate:
+		cursor.Date = todo.UpdateDate
+	}
+	return cursor
+}
+
+// Matches reports whether the cursor was taken from a list sorted by sortBy
+// and order.
+func (c TodoCursor) Matches(sortBy TodoSortField, order SortOrder) bool {
+	sortBy, order = sortKey(sortBy, order)
+	return c.SortBy == sortBy && c.SortOrder == order
+}
+
+// Encode returns the cursor as an opaque string that is safe in URLs.
+func (c TodoCursor) Encode() string {
+	data := cursorData{SortBy: c.SortBy, SortOrder: c.SortOrder, ID: c.ID, Before: c.Before}
+	if c.Title.Valid {
+		data.Title = &c.Title.String
+	}
+	if c.Date.Valid {
+		data.Date = &c.Date.Time
+	}
+	encoded, _ := json.Marshal(data)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// DecodeTodoCursor parses a cursor returned by Encode.
+func DecodeTodoCursor(value string) (TodoCursor, error) {
+	encoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return TodoCursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	var data cursorData
+	if err := json.Unmarshal(encoded, &data); err != nil {
+		return TodoCursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	if _, ok := todoSortColumns[data.SortBy]; !ok {
+		return TodoCursor{}, fmt.Errorf("%w: unknown sort field %q", ErrInvalidCursor, data.SortBy)
+	}
+	if _, ok := sortOrderKeywords[data.SortOrder]; !ok {
+		return TodoCursor{}, fmt.Errorf("%w: unknown sort order %q", ErrInvalidCursor, data.SortOrder)
+	}
+
+	cursor := TodoCursor{SortBy: data.SortBy, SortOrder: data.SortOrder, ID: data.ID, Before: data.Before}
+	if data.Title != nil {
+		cursor.Title = sql.NullString{String: *data.Title, Valid: true}
+	}
+	if data.Date != nil {
+		cursor.Date = sql.NullTime{Time: *data.Date, Valid: true}
+	}
+	return cursor, nil
+}
+
+// readOrder is the order the page is read in: the sort order, or its reverse
+// for the page before the cursor.
+func (c TodoCursor) readOrder() SortOrder {
+	_, order := sortKey(c.SortBy, c.SortOrder)
+	if c.Before {
+		return reverseOrder(order)
+	}
+	return order
+}
+
+// condition returns the WHERE condition of the todos that follow the cursor
+// in the read order. Like in MySQL, NULLs sort before every value, and ties
+// on the sort key are broken by id.
+func (c TodoCursor) condition() (string, []interface{}) {
+	sortBy, _ := sortKey(c.SortBy, c.SortOrder)
+	asc := c.readOrder() == SortAsc
+	op := ">"
+	if !asc {
+		op = "<"
+	}
+	if sortBy == TodoSortID {
+		return "id " + op + " ?", []interface{}{c.ID}
+	}
+
+	column := todoSortColumns[sortBy]
+	var value interface{}
+	valid := false
+	if sortBy == TodoSortTitle {
+		value, valid = c.Title.String, c.Title.Valid
+	} else {
+		value, valid = c.Date.Time, c.Date.Valid
+	}
+
+	switch {
+	case !valid && asc:
+		return "((" + column + " IS NULL AND id > ?) OR " + column + " IS NOT NULL)", []interface{}{c.ID}
+	case !valid:
+		return "(" + column + " IS NULL AND id < ?)", []interface{}{c.ID}
+	case asc:
+		return "(" + column + " > ? OR (" + column + " = ? AND id > ?))", []interface{}{value, value, c.ID}
+	}
+	return "(" + column + " < ? OR (" + column + " = ? AND id < ?) OR " + column + " IS NULL)", []interface{}{value, value, c.ID}
+}
+
+// TodoLister lists todos, like Repo does.
+type TodoLister interface {
+	ListTodo(ctx context.Context, arg ListTodoParams) ([]Todo, error)
+}
+
+// TodoPage is a page of a keyset paginated list. Next and Prev are the
+// cursors of the pages around it, nil when there is none.
+type TodoPage struct {
+	Todos []Todo
+	Next  *TodoCursor
+	Prev  *TodoCursor
+}
+
+// ListTodoPage lists the page of arg.Limit todos at arg.Cursor, or the first
+// page without a cursor. It reads one todo more than the page holds to find
+// out whether another page follows, so the list is never counted. Offset is
+// ignored.
+func ListTodoPage(ctx context.Context, lister TodoLister, arg ListTodoParams) (TodoPage, error) {
+	limit := int(arg.Limit)
+	arg.Offset = 0
+	arg.Limit++
+	items, err := lister.ListTodo(ctx, arg)
+	if err != nil {
+		return TodoPage{}, err
+	}
+
+	before := arg.Cursor != nil && arg.Cursor.Before
+	more := len(items) > limit
+	if more && before {
+		items = items[len(items)-limit:]
+	} else if more {
+		items = items[:limit]
+	}
+
+	page := TodoPage{Todos: items}
+	if len(items) == 0 {
+		return page, nil
+	}
+	sortBy, order := arg.sort()
+	if more && !before || before {
+		next := NewTodoCursor(items[len(items)-1], sortBy, order, false)
+		page.Next = &next
+	}
+	if more && before || arg.Cursor != nil && !before {
+		prev := NewTodoCursor(items[0], sortBy, order, true)
+		page.Prev = &prev
+	}
+	return page, nil
+}
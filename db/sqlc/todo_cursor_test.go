@@ -0,0 +1,182 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoCursorEncode(t *testing.T) {
+	date := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	todo := Todo{
+		ID:         7,
+		Title:      sql.NullString{String: "Buy milk", Valid: true},
+		CreateDate: sql.NullTime{Time: date, Valid: true},
+	}
+
+	testCases := []struct {
+		name   string
+		cursor TodoCursor
+		want   TodoCursor
+	}{
+		{
+			name:   "ID",
+			cursor: NewTodoCursor(todo, TodoSortID, SortDesc, false),
+			want:   TodoCursor{SortBy: TodoSortID, SortOrder: SortDesc, ID: 7},
+		},
+		{
+			name:   "Title",
+			cursor: NewTodoCursor(todo, TodoSortTitle, SortAsc, true),
+			want:   TodoCursor{SortBy: TodoSortTitle, SortOrder: SortAsc, ID: 7, Title: todo.Title, Before: true},
+		},
+		{
+			name:   "Date",
+			cursor: NewTodoCursor(todo, TodoSortCreateDate, SortAsc, false),
+			want:   TodoCursor{SortBy: TodoSortCreateDate, SortOrder: SortAsc, ID: 7, Date: todo.CreateDate},
+		},
+		{
+			name:   "NULL Date",
+			cursor: NewTodoCursor(todo, TodoSortUpdateDate, SortAsc, false),
+			want:   TodoCursor{SortBy: TodoSortUpdateDate, SortOrder: SortAsc, ID: 7},
+		},
+		{
+			name:   "Unknown Sort",
+			cursor: NewTodoCursor(todo, TodoSortField("priority"), SortOrder("up"), false),
+			want:   TodoCursor{SortBy: TodoSortID, SortOrder: SortAsc, ID: 7},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.cursor)
+			cursor, err := DecodeTodoCursor(tc.cursor.Encode())
+			require.NoError(t, err)
+			require.Equal(t, tc.want, cursor)
+		})
+	}
+
+	for _, value := range []string{
+		"",
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("[]")),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"s":"priority","o":"asc","i":1}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"s":"id","o":"up","i":1}`)),
+	} {
+		_, err := DecodeTodoCursor(value)
+		require.ErrorIs(t, err, ErrInvalidCursor, value)
+	}
+
+	cursor := NewTodoCursor(todo, "", "", false)
+	require.True(t, cursor.Matches(TodoSortID, SortAsc))
+	require.True(t, cursor.Matches("", ""))
+	require.False(t, cursor.Matches(TodoSortID, SortDesc))
+}
+
+// listerFunc lists the todos with a func, to fake the repo.
+type listerFunc func(arg ListTodoParams) []Todo
+
+func (f listerFunc) ListTodo(ctx context.Context, arg ListTodoParams) ([]Todo, error) {
+	return f(arg), nil
+}
+
+func TestListTodoPage(t *testing.T) {
+	todos := func(ids ...int64) []Todo {
+		items := make([]Todo, len(ids))
+		for i, id := range ids {
+			items[i] = Todo{ID: id}
+		}
+		return items
+	}
+	after := &TodoCursor{SortBy: TodoSortID, SortOrder: SortAsc, ID: 2}
+	before := &TodoCursor{SortBy: TodoSortID, SortOrder: SortAsc, ID: 9, Before: true}
+
+	testCases := []struct {
+		name     string
+		cursor   *TodoCursor
+		listed   []Todo
+		wantIDs  []int64
+		wantNext *TodoCursor
+		wantPrev *TodoCursor
+	}{
+		{
+			name:     "First",
+			listed:   todos(1, 2, 3),
+			wantIDs:  []int64{1, 2},
+			wantNext: &TodoCursor{SortBy: TodoSortID, SortOrder: SortAsc, ID: 2},
+		},
+		{
+			name:    "Only",
+			listed:  todos(1, 2),
+			wantIDs: []int64{1, 2},
+		},
+		{
+			name:     "After",
+			cursor:   after,
+			listed:   todos(3, 4, 5),
+			wantIDs:  []int64{3, 4},
+			wantNext: &TodoCursor{SortBy: TodoSortID, SortOrder: SortAsc, ID: 4},
+			wantPrev: &TodoCursor{SortBy: TodoSortID, SortOrder: SortAsc, ID: 3, Before: true},
+		},
+		{
+			name:     "Last",
+			cursor:   after,
+			listed:   todos(3),
+			wantIDs:  []int64{3},
+			wantPrev: &TodoCursor{SortBy: TodoSortID, SortOrder: SortAsc, ID: 3, Before: true},
+		},
+		{
+			name:     "Before",
+			cursor:   before,
+			listed:   todos(6, 7, 8),
+			wantIDs:  []int64{7, 8},
+			wantNext: &TodoCursor{SortBy: TodoSortID, SortOrder: SortAsc, ID: 8},
+			wantPrev: &TodoCursor{SortBy: TodoSortID, SortOrder: SortAsc, ID: 7, Before: true},
+		},
+		{
+			name:     "Before First",
+			cursor:   before,
+			listed:   todos(1, 2),
+			wantIDs:  []int64{1, 2},
+			wantNext: &TodoCursor{SortBy: TodoSortID, SortOrder: SortAsc, ID: 2},
+		},
+		{
+			name:    "Empty",
+			cursor:  after,
+			wantIDs: []int64{},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			lister := listerFunc(func(arg ListTodoParams) []Todo {
+				require.Equal(t, int32(3), arg.Limit)
+				require.Zero(t, arg.Offset)
+				require.Equal(t, tc.cursor, arg.Cursor)
+				return tc.listed
+			})
+
+			page, err := ListTodoPage(context.Background(), lister, ListTodoParams{
+				TodoFilter: TodoFilter{UserID: 1},
+				Offset:     4,
+				Limit:      2,
+				Cursor:     tc.cursor,
+			})
+			require.NoError(t, err)
+			ids := []int64{}
+			for _, todo := range page.Todos {
+				ids = append(ids, todo.ID)
+			}
+			require.Equal(t, tc.wantIDs, ids)
+			require.Equal(t, tc.wantNext, page.Next)
+			require.Equal(t, tc.wantPrev, page.Prev)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// ListTodo and CountTodo are written by hand instead of generated by sqlc,
+// since the ORDER BY and WHERE clauses depend on the request. Sort columns and
+// directions are only ever taken from the whitelists below, user input is
+// passed to the database as bind parameters.
+
+type TodoSortField string
+
+const (
+	TodoSortID         TodoSortField = "id"
+	TodoSortCreateDate TodoSortField = "create_date"
+	TodoSortUpdateDate TodoSortField = "update_date"
+	TodoSortTitle      TodoSortField = "title"
+)
+
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// searchCondition matches the query against the FULLTEXT index of the MySQL
+// schema. The other dialects swap it for their own search.
+const searchCondition = "MATCH(title, description) AGAINST (? IN NATURAL LANGUAGE MODE)"
+
+var todoSortColumns = map[TodoSortField]string{
+	TodoSortID:         "id",
+	TodoSortCreateDate: "create_date",
+	TodoSortUpdateDate: "update_date",
+	TodoSortTitle:      "title",
+}
+
+var sortOrderKeywords = map[SortOrder]string{
+	SortAsc:  "ASC",
+	SortDesc: "DESC",
+}
+
+const todoColumns = "id, title, description, create_date, update_date, user_id, status, priority, due_at, deleted_at"
+
+// TodoFilter narrows down the todos of a user, trashed todos are never
+// listed. Zero values disable a filter.
+// The From bounds are inclusive and the To bounds are exclusive.
+type TodoFilter struct {
+	UserID      int64        `json:"user_id"`
+	Query       string       `json:"query"`
+	Tag         string       `json:"tag"`
+	CreatedFrom sql.NullTime `json:"created_from"`
+	CreatedTo   sql.NullTime `json:"created_to"`
+	DueFrom     sql.NullTime `json:"due_from"`
+	DueTo       sql.NullTime `json:"due_to"`
+}
+
+type ListTodoParams struct {
+	TodoFilter
+	SortBy    TodoSortField `json:"sort_by"`
+	SortOrder SortOrder     `json:"sort_order"`
+	Offset    int32         `json:"offset"`
+	Limit     int32         `json:"limit"`
+	// Cursor switches to keyset pagination: the page starts after the todo
+	// of the cursor, or ends before it, and Offset is ignored. The cursor
+	// brings the sort of the list it was taken from, which replaces SortBy and
+	// SortOrder.
+	Cursor *TodoCursor `json:"cursor"`
+}
+
+func (f TodoFilter) where() (string, []interface{}) {
+	conditions := []string{"user_id = ?", "deleted_at IS NULL"}
+	args := []interface{}{f.UserID}
+
+	if len(f.Query) > 0 {
+		conditions = append(conditions, searchCondition)
+		args = append(args, f.Query)
+	}
+	if len(f.Tag) > 0 {
+		conditions = append(conditions, "id IN (SELECT todo_tags.todo_id FROM todo_tags"+
+			" JOIN tags ON tags.id = todo_tags.tag_id WHERE tags.user_id = ? AND tags.name = ?)")
+		args = append(args, f.UserID, f.Tag)
+	}
+	if f.CreatedFrom.Valid {
+		conditions = append(conditions, "create_date >= ?")
+		args = append(args, f.CreatedFrom.Time)
+	}
+	if f.CreatedTo.Valid {
+		conditions = append(conditions, "create_date < ?")
+		args = append(args, f.CreatedTo.Time)
+	}
+	if f.DueFrom.Valid {
+		conditions = append(conditions, "due_at >= ?")
+		args = append(args, f.DueFrom.Time)
+	}
+	if f.DueTo.Valid {
+		conditions = append(conditions, "due_at < ?")
+		args = append(args, f.DueTo.Time)
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// sort returns the sort of the list, from the cursor when there is one.
+func (arg ListTodoParams) sort() (TodoSortField, SortOrder) {
+	if arg.Cursor != nil {
+		return sortKey(arg.Cursor.SortBy, arg.Cursor.SortOrder)
+	}
+	return sortKey(arg.SortBy, arg.SortOrder)
+}
+
+// orderBy falls back to id ascending for unknown sort fields and orders.
+// Other columns are not unique, so id is added to keep the pages stable.
+// The page before a cursor is read in reverse and flipped back by ListTodo.
+func (arg ListTodoParams) orderBy() string {
+	sortBy, order := arg.sort()
+	if arg.Cursor != nil {
+		order = arg.Cursor.readOrder()
+	}
+	column := todoSortColumns[sortBy]
+	keyword := sortOrderKeywords[order]
+
+	if column == todoSortColumns[TodoSortID] {
+		return "ORDER BY id " + keyword
+	}
+	return "ORDER BY " + column + " " + keyword + ", id " + keyword
+}
+
+func buildCountTodo(arg TodoFilter) (string, []interface{}) {
+	where, args := arg.where()
+	return "SELECT count(*) FROM Todo " + where, args
+}
+
+func buildListTodo(arg ListTodoParams) (string, []interface{}) {
+	where, args := arg.where()
+	offset := arg.Offset
+	if arg.Cursor != nil {
+		condition, cursorArgs := arg.Cursor.condition()
+		where += " AND " + condition
+		args = append(args, cursorArgs...)
+		offset = 0
+	}
+	query := "SELECT " + todoColumns + " FROM Todo " + where + " " + arg.orderBy() + " LIMIT ?, ?"
+	return query, append(args, offset, arg.Limit)
+}
+
+func (q *Queries) CountTodo(ctx context.Context, arg TodoFilter) (int64, error) {
+	query, args := buildCountTodo(arg)
+	row := q.db.QueryRowContext(ctx, namedQuery("CountTodo", query), args...)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+func (q *Queries) ListTodo(ctx context.Context, arg ListTodoParams) ([]Todo, error) {
+	query, args := buildListTodo(arg)
+	rows, err := q.db.QueryContext(ctx, namedQuery("ListTodo", query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.CreateDate,
+			&i.UpdateDate,
+			&i.UserID,
+			&i.Status,
+			&i.Priority,
+			&i.DueAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if arg.Cursor != nil && arg.Cursor.Before {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,172 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildListTodo(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name      string
+		arg       ListTodoParams
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			name: "Default",
+			arg: ListTodoParams{
+				TodoFilter: TodoFilter{UserID: 1},
+				Offset:     0,
+				Limit:      5,
+			},
+			wantQuery: "SELECT " + todoColumns + " FROM Todo WHERE user_id = ? AND deleted_at IS NULL ORDER BY id ASC LIMIT ?, ?",
+			wantArgs:  []interface{}{int64(1), int32(0), int32(5)},
+		},
+		{
+			name: "Sort and filters",
+			arg: ListTodoParams{
+				TodoFilter: TodoFilter{
+					UserID:      1,
+					Query:       "dog",
+					CreatedFrom: sql.NullTime{Time: from, Valid: true},
+					CreatedTo:   sql.NullTime{Time: to, Valid: true},
+					DueTo:       sql.NullTime{Time: to, Valid: true},
+				},
+				SortBy:    TodoSortUpdateDate,
+				SortOrder: SortDesc,
+				Offset:    10,
+				Limit:     5,
+			},
+			wantQuery: "SELECT " + todoColumns + " FROM Todo WHERE user_id = ? AND deleted_at IS NULL" +
+				" AND MATCH(title, description) AGAINST (? IN NATURAL LANGUAGE MODE)" +
+				" AND create_date >= ? AND create_date < ? AND due_at < ?" +
+				" ORDER BY update_date DESC, id DESC LIMIT ?, ?",
+			wantArgs: []interface{}{int64(1), "dog", from, to, to, int32(10), int32(5)},
+		},
+		{
+			name: "Tag",
+			arg: ListTodoParams{
+				TodoFilter: TodoFilter{UserID: 1, Tag: "work"},
+				Limit:      5,
+			},
+			wantQuery: "SELECT " + todoColumns + " FROM Todo WHERE user_id = ? AND deleted_at IS NULL" +
+				" AND id IN (SELECT todo_tags.todo_id FROM todo_tags" +
+				" JOIN tags ON tags.id = todo_tags.tag_id WHERE tags.user_id = ? AND tags.name = ?)" +
+				" ORDER BY id ASC LIMIT ?, ?",
+			wantArgs: []interface{}{int64(1), int64(1), "work", int32(0), int32(5)},
+		},
+		{
+			name: "Cursor",
+			arg: ListTodoParams{
+				TodoFilter: TodoFilter{UserID: 1},
+				SortBy:     TodoSortID,
+				Offset:     10,
+				Limit:      6,
+				Cursor: &TodoCursor{
+					SortBy:    TodoSortTitle,
+					SortOrder: SortAsc,
+					ID:        7,
+					Title:     sql.NullString{String: "milk", Valid: true},
+				},
+			},
+			wantQuery: "SELECT " + todoColumns + " FROM Todo WHERE user_id = ? AND deleted_at IS NULL" +
+				" AND (title > ? OR (title = ? AND id > ?))" +
+				" ORDER BY title ASC, id ASC LIMIT ?, ?",
+			wantArgs: []interface{}{int64(1), "milk", "milk", int64(7), int32(0), int32(6)},
+		},
+		{
+			name: "Cursor Before",
+			arg: ListTodoParams{
+				TodoFilter: TodoFilter{UserID: 1},
+				Limit:      6,
+				Cursor: &TodoCursor{
+					SortBy:    TodoSortUpdateDate,
+					SortOrder: SortDesc,
+					ID:        7,
+					Date:      sql.NullTime{Time: from, Valid: true},
+					Before:    true,
+				},
+			},
+			wantQuery: "SELECT " + todoColumns + " FROM Todo WHERE user_id = ? AND deleted_at IS NULL" +
+				" AND (update_date > ? OR (update_date = ? AND id > ?))" +
+				" ORDER BY update_date ASC, id ASC LIMIT ?, ?",
+			wantArgs: []interface{}{int64(1), from, from, int64(7), int32(0), int32(6)},
+		},
+		{
+			name: "Cursor NULL",
+			arg: ListTodoParams{
+				TodoFilter: TodoFilter{UserID: 1},
+				Limit:      6,
+				Cursor:     &TodoCursor{SortBy: TodoSortUpdateDate, SortOrder: SortDesc, ID: 7},
+			},
+			wantQuery: "SELECT " + todoColumns + " FROM Todo WHERE user_id = ? AND deleted_at IS NULL" +
+				" AND (update_date IS NULL AND id < ?)" +
+				" ORDER BY update_date DESC, id DESC LIMIT ?, ?",
+			wantArgs: []interface{}{int64(1), int64(7), int32(0), int32(6)},
+		},
+		{
+			name: "Cursor ID",
+			arg: ListTodoParams{
+				TodoFilter: TodoFilter{UserID: 1},
+				Limit:      6,
+				Cursor:     &TodoCursor{SortBy: TodoSortID, SortOrder: SortAsc, ID: 7, Before: true},
+			},
+			wantQuery: "SELECT " + todoColumns + " FROM Todo WHERE user_id = ? AND deleted_at IS NULL" +
+				" AND id < ? ORDER BY id DESC LIMIT ?, ?",
+			wantArgs: []interface{}{int64(1), int64(7), int32(0), int32(6)},
+		},
+		{
+			name: "Unknown sort falls back to id",
+			arg: ListTodoParams{
+				TodoFilter: TodoFilter{UserID: 1},
+				SortBy:     TodoSortField("id; DROP TABLE Todo"),
+				SortOrder:  SortOrder("sideways"),
+				Limit:      5,
+			},
+			wantQuery: "SELECT " + todoColumns + " FROM Todo WHERE user_id = ? AND deleted_at IS NULL ORDER BY id ASC LIMIT ?, ?",
+			wantArgs:  []interface{}{int64(1), int32(0), int32(5)},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			query, args := buildListTodo(tc.arg)
+			require.Equal(t, tc.wantQuery, query)
+			require.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
+
+func TestBuildCountTodo(t *testing.T) {
+	query, args := buildCountTodo(TodoFilter{UserID: 1, Query: "dog"})
+	require.Equal(t, "SELECT count(*) FROM Todo WHERE user_id = ? AND deleted_at IS NULL AND MATCH(title, description) AGAINST (? IN NATURAL LANGUAGE MODE)", query)
+	require.Equal(t, []interface{}{int64(1), "dog"}, args)
+}
+
+func TestBuildListTagsByTodoIDs(t *testing.T) {
+	query, args := buildListTagsByTodoIDs(ListTagsByTodoIDsParams{UserID: 1, TodoIDs: []int64{3, 5}})
+	require.Equal(t, "SELECT todo_tags.todo_id, tags.id, tags.user_id, tags.name, tags.create_date FROM tags"+
+		" JOIN todo_tags ON todo_tags.tag_id = tags.id"+
+		" WHERE todo_tags.todo_id IN (?, ?) AND tags.user_id = ? ORDER BY tags.name", query)
+	require.Equal(t, []interface{}{int64(3), int64(5), int64(1)}, args)
+}
+
+func TestBuildDeleteTodoList(t *testing.T) {
+	arg := DeleteTodoListParams{UserID: 1, IDs: []int64{3, 5, 8}}
+
+	query, args := buildLockTodoList(arg)
+	require.Equal(t, "SELECT id FROM Todo WHERE user_id = ? AND deleted_at IS NULL AND id IN (?, ?, ?) FOR UPDATE", query)
+	require.Equal(t, []interface{}{int64(1), int64(3), int64(5), int64(8)}, args)
+
+	query, args = buildDeleteTodoList(arg)
+	require.Equal(t, "UPDATE Todo SET deleted_at = CURRENT_TIMESTAMP, update_date = update_date WHERE user_id = ? AND deleted_at IS NULL AND id IN (?, ?, ?)", query)
+	require.Equal(t, []interface{}{int64(1), int64(3), int64(5), int64(8)}, args)
+}
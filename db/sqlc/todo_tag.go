@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"strings"
+)
+
+// ListTagsByTodoIDs is written by hand since sqlc cannot expand a slice into
+// an IN list for MySQL. It loads the tags of a whole page of todos at once.
+
+type ListTagsByTodoIDsParams struct {
+	UserID  int64   `json:"user_id"`
+	TodoIDs []int64 `json:"todo_ids"`
+}
+
+// inList returns the placeholders and bind parameters of an IN list.
+func inList(ids []int64) (string, []interface{}) {
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	return "(" + strings.Join(placeholders, ", ") + ")", args
+}
+
+func buildListTagsByTodoIDs(arg ListTagsByTodoIDsParams) (string, []interface{}) {
+	in, args := inList(arg.TodoIDs)
+	args = append(args, arg.UserID)
+
+	query := "SELECT todo_tags.todo_id, tags.id, tags.user_id, tags.name, tags.create_date FROM tags" +
+		" JOIN todo_tags ON todo_tags.tag_id = tags.id" +
+		" WHERE todo_tags.todo_id IN " + in + " AND tags.user_id = ?" +
+		" ORDER BY tags.name"
+	return query, args
+}
+
+// ListTagsByTodoIDs returns the tags of each todo keyed by the todo ID. Todos
+// without tags have no entry.
+func (q *Queries) ListTagsByTodoIDs(ctx context.Context, arg ListTagsByTodoIDsParams) (map[int64][]Tag, error) {
+	items := map[int64][]Tag{}
+	if len(arg.TodoIDs) == 0 {
+		return items, nil
+	}
+
+	query, args := buildListTagsByTodoIDs(arg)
+	rows, err := q.db.QueryContext(ctx, namedQuery("ListTagsByTodoIDs", query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var todoID int64
+		var i Tag
+		if err := rows.Scan(
+			&todoID,
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.CreateDate,
+		); err != nil {
+			return nil, err
+		}
+		items[todoID] = append(items[todoID], i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
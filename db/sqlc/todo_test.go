@@ -4,20 +4,21 @@ import (
 	"context"
 	"database/sql"
 	"first-app/todo_go/util"
-	"strconv"
-	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
 
-func createRandomTodo(t *testing.T) int64 {
+func createRandomTodo(t *testing.T, q Querier, user User) int64 {
 	arg := CreateTodoParams{
+		UserID:      user.ID,
 		Title:       util.RandomTitle(),
 		Description: util.RandomDescription(),
+		Priority:    TodoPriorityMedium,
 	}
 
-	result, err := testQueries.CreateTodo(context.Background(), arg)
+	result, err := q.CreateTodo(context.Background(), arg)
 	require.NoError(t, err)
 	id, _ := result.LastInsertId()
 
@@ -25,88 +26,442 @@ func createRandomTodo(t *testing.T) int64 {
 }
 
 func TestCreateTodo(t *testing.T) {
-	createRandomTodo(t)
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	createRandomTodo(t, repo, user)
 }
 
 func TestGetTodo(t *testing.T) {
-	lastId := createRandomTodo(t)
-	todo, err := testQueries.GetTodo(context.Background(), lastId)
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	lastId := createRandomTodo(t, repo, user)
+	todo, err := repo.GetTodo(context.Background(), GetTodoParams{ID: lastId, UserID: user.ID})
 	require.NoError(t, err)
 	require.NotEmpty(t, todo)
+	require.Equal(t, TodoStatusOpen, todo.Status)
+	require.Equal(t, TodoPriorityMedium, todo.Priority)
+	require.False(t, todo.DueAt.Valid)
+
+	other := createRandomUser(t, repo)
+	_, err = repo.GetTodo(context.Background(), GetTodoParams{ID: lastId, UserID: other.ID})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
 }
 
 func TestUpdateTodo(t *testing.T) {
-	lastId := createRandomTodo(t)
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	lastId := createRandomTodo(t, repo, user)
 
 	arg := UpdateTodoParams{
 		ID:          lastId,
+		UserID:      user.ID,
 		Description: util.RandomDescription(),
+		Status:      TodoStatusInProgress,
+		Priority:    TodoPriorityHigh,
+		DueAt:       sql.NullTime{Time: time.Now().Add(24 * time.Hour).Truncate(time.Second), Valid: true},
 	}
 
-	err := testQueries.UpdateTodo(context.Background(), arg)
+	err := repo.UpdateTodo(context.Background(), arg)
+	require.NoError(t, err)
+
+	todo, err := repo.GetTodo(context.Background(), GetTodoParams{ID: lastId, UserID: user.ID})
 	require.NoError(t, err)
+	require.Equal(t, arg.Status, todo.Status)
+	require.Equal(t, arg.Priority, todo.Priority)
+	require.WithinDuration(t, arg.DueAt.Time, todo.DueAt.Time, time.Second)
+}
+
+func TestToggleTodo(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	lastId := createRandomTodo(t, repo, user)
+	arg := ToggleTodoParams{ID: lastId, UserID: user.ID}
+
+	err := repo.ToggleTodo(context.Background(), arg)
+	require.NoError(t, err)
+	todo, err := repo.GetTodo(context.Background(), GetTodoParams{ID: lastId, UserID: user.ID})
+	require.NoError(t, err)
+	require.Equal(t, TodoStatusDone, todo.Status)
+
+	err = repo.ToggleTodo(context.Background(), arg)
+	require.NoError(t, err)
+	todo, err = repo.GetTodo(context.Background(), GetTodoParams{ID: lastId, UserID: user.ID})
+	require.NoError(t, err)
+	require.Equal(t, TodoStatusOpen, todo.Status)
 }
 
 func TestDeleteTodo(t *testing.T) {
-	lastId := createRandomTodo(t)
-	err := testQueries.DeleteTodo(context.Background(), lastId)
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	lastId := createRandomTodo(t, repo, user)
+	err := repo.DeleteTodo(context.Background(), DeleteTodoParams{ID: lastId, UserID: user.ID})
 	require.NoError(t, err)
 
-	todo, err := testQueries.GetTodo(context.Background(), lastId)
+	todo, err := repo.GetTodo(context.Background(), GetTodoParams{ID: lastId, UserID: user.ID})
 	require.Error(t, err)
 	require.EqualError(t, err, sql.ErrNoRows.Error())
 	require.Empty(t, todo)
+
+	deleted, err := repo.CountDeletedTodo(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
 }
 
 func TestDeleteTodoList(t *testing.T) {
-	testQueries.ClearTodo(context.Background())
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
 	for i := 0; i < 3; i++ {
-		createRandomTodo(t)
+		createRandomTodo(t, repo, user)
 	}
 
 	arg := ListTodoParams{
-		Offset: 0,
-		Limit:  3,
+		TodoFilter: TodoFilter{UserID: user.ID},
+		Offset:     0,
+		Limit:      3,
 	}
-	todoList, _ := testQueries.ListTodo(context.Background(), arg)
+	todoList, _ := repo.ListTodo(context.Background(), arg)
 
-	idSlice := []string{}
+	ids := []int64{}
 	for _, todo := range todoList {
-		idSlice = append(idSlice, strconv.Itoa(int(todo.ID)))
+		ids = append(ids, todo.ID)
 	}
 
-	ids := strings.Join(idSlice, ",")
-	err := testQueries.DeleteTodoList(context.Background(), ids)
+	deleted, err := repo.DeleteTodoList(context.Background(), DeleteTodoListParams{UserID: user.ID, IDs: ids})
 	require.NoError(t, err)
+	require.Equal(t, int64(3), deleted)
 
-	total, _ := testQueries.CountTodo(context.Background())
+	total, _ := repo.CountTodo(context.Background(), TodoFilter{UserID: user.ID})
 	require.Equal(t, int64(0), total)
+
+	trashed, _ := repo.CountDeletedTodo(context.Background(), user.ID)
+	require.Equal(t, int64(3), trashed)
+}
+
+func TestDeleteTodoListTx(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	first := createRandomTodo(t, repo, user)
+	second := createRandomTodo(t, repo, user)
+	trashed := createRandomTodo(t, repo, user)
+	err := repo.DeleteTodo(context.Background(), DeleteTodoParams{ID: trashed, UserID: user.ID})
+	require.NoError(t, err)
+
+	other := createRandomUser(t, repo)
+	foreign := createRandomTodo(t, repo, other)
+
+	result, err := repo.DeleteTodoListTx(context.Background(), DeleteTodoListParams{
+		UserID: user.ID,
+		IDs:    []int64{first, second, trashed, foreign},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), result.Deleted)
+	require.ElementsMatch(t, []int64{trashed, foreign}, result.NotFound)
+
+	_, err = repo.GetTodo(context.Background(), GetTodoParams{ID: foreign, UserID: other.ID})
+	require.NoError(t, err)
+}
+
+func TestRestoreTodo(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	lastId := createRandomTodo(t, repo, user)
+	err := repo.DeleteTodo(context.Background(), DeleteTodoParams{ID: lastId, UserID: user.ID})
+	require.NoError(t, err)
+
+	trash, err := repo.ListDeletedTodo(context.Background(), ListDeletedTodoParams{UserID: user.ID, Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	require.Equal(t, lastId, trash[0].ID)
+	require.True(t, trash[0].DeletedAt.Valid)
+
+	// Another user cannot restore the todo.
+	other := createRandomUser(t, repo)
+	restored, err := repo.RestoreTodo(context.Background(), RestoreTodoParams{ID: lastId, UserID: other.ID})
+	require.NoError(t, err)
+	require.Zero(t, restored)
+
+	restored, err = repo.RestoreTodo(context.Background(), RestoreTodoParams{ID: lastId, UserID: user.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), restored)
+
+	todo, err := repo.GetTodo(context.Background(), GetTodoParams{ID: lastId, UserID: user.ID})
+	require.NoError(t, err)
+	require.False(t, todo.DeletedAt.Valid)
+}
+
+func TestPurgeTodo(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	lastId := createRandomTodo(t, repo, user)
+
+	// Only trashed todos can be purged.
+	purged, err := repo.PurgeTodo(context.Background(), PurgeTodoParams{ID: lastId, UserID: user.ID})
+	require.NoError(t, err)
+	require.Zero(t, purged)
+
+	err = repo.DeleteTodo(context.Background(), DeleteTodoParams{ID: lastId, UserID: user.ID})
+	require.NoError(t, err)
+
+	purged, err = repo.PurgeTodo(context.Background(), PurgeTodoParams{ID: lastId, UserID: user.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), purged)
+
+	deleted, _ := repo.CountDeletedTodo(context.Background(), user.ID)
+	require.Zero(t, deleted)
+}
+
+func TestPurgeDeletedTodo(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	lastId := createRandomTodo(t, repo, user)
+	err := repo.DeleteTodo(context.Background(), DeleteTodoParams{ID: lastId, UserID: user.ID})
+	require.NoError(t, err)
+
+	_, err = repo.PurgeDeletedTodo(context.Background(), int64(48*time.Hour/time.Second))
+	require.NoError(t, err)
+	deleted, _ := repo.CountDeletedTodo(context.Background(), user.ID)
+	require.Equal(t, int64(1), deleted)
+
+	_, err = repo.PurgeDeletedTodo(context.Background(), -60)
+	require.NoError(t, err)
+	deleted, _ = repo.CountDeletedTodo(context.Background(), user.ID)
+	require.Zero(t, deleted)
+}
+
+// TestPurgeDeletedTodoTimezone reads the dates in a location 24 hours ahead of
+// the session time zone that writes deleted_at, so a cutoff worked out on the
+// client would purge the todos trashed right now.
+func TestPurgeDeletedTodoTimezone(t *testing.T) {
+	config := createTestDatabase(t)
+	config.DBTimezone = "Pacific/Kiritimati"
+
+	migrator, err := NewMigrator(config)
+	require.NoError(t, err)
+	defer migrator.Close()
+	require.NoError(t, migrator.Up())
+
+	cfg, err := mysqlConfig(config)
+	require.NoError(t, err)
+	cfg.Params = map[string]string{"time_zone": "'-10:00'"}
+	conn, err := sql.Open("mysql", cfg.FormatDSN())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	ctx := context.Background()
+	repo := newSQLRepo(mysqlDialect, conn, nil)
+	user := createRandomUser(t, repo)
+	fresh := createRandomTodo(t, repo, user)
+	old := createRandomTodo(t, repo, user)
+	for _, id := range []int64{fresh, old} {
+		require.NoError(t, repo.DeleteTodo(ctx, DeleteTodoParams{ID: id, UserID: user.ID}))
+	}
+	_, err = conn.ExecContext(ctx, "UPDATE Todo SET deleted_at = CURRENT_TIMESTAMP - INTERVAL 2 HOUR WHERE id = ?", old)
+	require.NoError(t, err)
+
+	purged, err := repo.PurgeDeletedTodo(ctx, int64(time.Hour/time.Second))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), purged)
+
+	todos, err := repo.ListDeletedTodo(ctx, ListDeletedTodoParams{UserID: user.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, todos, 1)
+	require.Equal(t, fresh, todos[0].ID)
 }
 
 func TestListTodo(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
 	for i := 0; i < 10; i++ {
-		createRandomTodo(t)
+		createRandomTodo(t, repo, user)
 	}
 
 	arg := ListTodoParams{
-		Offset: 5,
-		Limit:  5,
+		TodoFilter: TodoFilter{UserID: user.ID},
+		Offset:     5,
+		Limit:      5,
 	}
-	todoList, err := testQueries.ListTodo(context.Background(), arg)
+	todoList, err := repo.ListTodo(context.Background(), arg)
 	require.NoError(t, err)
 	require.Len(t, todoList, 5)
 
 	for _, todo := range todoList {
 		require.NotEmpty(t, todo)
+		require.Equal(t, user.ID, todo.UserID)
 	}
 }
 
 func TestCountTodo(t *testing.T) {
-	err := testQueries.ClearTodo(context.Background())
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	createRandomTodo(t, repo, user)
+	createRandomTodo(t, repo, user)
+	other := createRandomUser(t, repo)
+	createRandomTodo(t, repo, other)
+
+	total, err := repo.CountTodo(context.Background(), TodoFilter{UserID: user.ID})
 	require.NoError(t, err)
+	require.Equal(t, int64(2), total)
 
-	createRandomTodo(t)
-	total, err := testQueries.CountTodo(context.Background())
+	err = repo.ClearTodo(context.Background(), user.ID)
+	require.NoError(t, err)
+	total, err = repo.CountTodo(context.Background(), TodoFilter{UserID: user.ID})
+	require.NoError(t, err)
+	require.Zero(t, total)
+	total, err = repo.CountTodo(context.Background(), TodoFilter{UserID: other.ID})
 	require.NoError(t, err)
 	require.Equal(t, int64(1), total)
 }
+
+func TestSearchTodo(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	keyword := util.RandomString(12)
+	for i := 0; i < 3; i++ {
+		createRandomTodo(t, repo, user)
+	}
+	for i := 0; i < 2; i++ {
+		arg := CreateTodoParams{
+			UserID:      user.ID,
+			Title:       sql.NullString{String: "walk " + keyword, Valid: true},
+			Description: util.RandomDescription(),
+			Priority:    TodoPriorityMedium,
+		}
+		_, err := repo.CreateTodo(context.Background(), arg)
+		require.NoError(t, err)
+	}
+
+	total, err := repo.CountTodo(context.Background(), TodoFilter{UserID: user.ID, Query: keyword})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), total)
+
+	arg := ListTodoParams{
+		TodoFilter: TodoFilter{UserID: user.ID, Query: keyword},
+		Offset:     0,
+		Limit:      5,
+	}
+	todoList, err := repo.ListTodo(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, todoList, 2)
+	for _, todo := range todoList {
+		require.Contains(t, todo.Title.String, keyword)
+	}
+
+	other := createRandomUser(t, repo)
+	total, err = repo.CountTodo(context.Background(), TodoFilter{UserID: other.ID, Query: keyword})
+	require.NoError(t, err)
+	require.Zero(t, total)
+}
+
+func TestListTodoSortAndFilter(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	for i := 0; i < 5; i++ {
+		createRandomTodo(t, repo, user)
+	}
+
+	arg := ListTodoParams{
+		TodoFilter: TodoFilter{UserID: user.ID},
+		SortBy:     TodoSortTitle,
+		SortOrder:  SortDesc,
+		Offset:     0,
+		Limit:      5,
+	}
+	todoList, err := repo.ListTodo(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, todoList, 5)
+	for i := 1; i < len(todoList); i++ {
+		require.GreaterOrEqual(t, todoList[i-1].Title.String, todoList[i].Title.String)
+	}
+
+	tomorrow := sql.NullTime{Time: time.Now().AddDate(0, 0, 1), Valid: true}
+	total, err := repo.CountTodo(context.Background(), TodoFilter{UserID: user.ID, CreatedFrom: tomorrow})
+	require.NoError(t, err)
+	require.Zero(t, total)
+
+	total, err = repo.CountTodo(context.Background(), TodoFilter{UserID: user.ID, CreatedTo: tomorrow})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), total)
+}
+
+func TestListTodoFixtures(t *testing.T) {
+	repo := newTestRepo(t)
+	loadFixtures(t, repo.db, "todos")
+	date := func(day, hour int) sql.NullTime {
+		return sql.NullTime{Time: time.Date(2024, 5, day, hour, 0, 0, 0, time.UTC), Valid: true}
+	}
+
+	testCases := []struct {
+		name    string
+		arg     ListTodoParams
+		wantIDs []int64
+	}{
+		{
+			name:    "Default",
+			arg:     ListTodoParams{TodoFilter: TodoFilter{UserID: 1}},
+			wantIDs: []int64{1, 2, 3},
+		},
+		{
+			name:    "Title",
+			arg:     ListTodoParams{TodoFilter: TodoFilter{UserID: 1}, SortBy: TodoSortTitle},
+			wantIDs: []int64{2, 1, 3},
+		},
+		{
+			name:    "Create Date Desc",
+			arg:     ListTodoParams{TodoFilter: TodoFilter{UserID: 1}, SortBy: TodoSortCreateDate, SortOrder: SortDesc},
+			wantIDs: []int64{3, 2, 1},
+		},
+		{
+			name:    "Offset",
+			arg:     ListTodoParams{TodoFilter: TodoFilter{UserID: 1}, SortBy: TodoSortTitle, Offset: 1, Limit: 1},
+			wantIDs: []int64{1},
+		},
+		{
+			name:    "Search",
+			arg:     ListTodoParams{TodoFilter: TodoFilter{UserID: 1, Query: "milk"}},
+			wantIDs: []int64{1},
+		},
+		{
+			name:    "Tag",
+			arg:     ListTodoParams{TodoFilter: TodoFilter{UserID: 1, Tag: "work"}},
+			wantIDs: []int64{2},
+		},
+		{
+			name:    "Created",
+			arg:     ListTodoParams{TodoFilter: TodoFilter{UserID: 1, CreatedFrom: date(2, 9), CreatedTo: date(3, 9)}},
+			wantIDs: []int64{2},
+		},
+		{
+			name:    "Due",
+			arg:     ListTodoParams{TodoFilter: TodoFilter{UserID: 1, DueTo: date(6, 0)}},
+			wantIDs: []int64{3},
+		},
+		{
+			name:    "Other User",
+			arg:     ListTodoParams{TodoFilter: TodoFilter{UserID: 2, Query: "milk"}},
+			wantIDs: []int64{5},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.arg.Limit == 0 {
+				tc.arg.Limit = 10
+			}
+			todoList, err := repo.ListTodo(context.Background(), tc.arg)
+			require.NoError(t, err)
+			ids := []int64{}
+			for _, todo := range todoList {
+				ids = append(ids, todo.ID)
+			}
+			require.Equal(t, tc.wantIDs, ids)
+
+			if tc.arg.Offset == 0 {
+				total, err := repo.CountTodo(context.Background(), tc.arg.TodoFilter)
+				require.NoError(t, err)
+				require.Equal(t, int64(len(tc.wantIDs)), total)
+			}
+		})
+	}
+}
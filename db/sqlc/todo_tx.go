@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+)
+
+// CreateTodoTxParams contains the input parameters of CreateTodoTx.
+type CreateTodoTxParams struct {
+	CreateTodoParams
+	TagNames []string `json:"tag_names"`
+}
+
+// CreateTodoTxResult is the result of CreateTodoTx.
+type CreateTodoTxResult struct {
+	Todo Todo  `json:"todo"`
+	Tags []Tag `json:"tags"`
+}
+
+// attachTagsByName attaches the named tags of the user to the todo, creating
+// the tags that do not exist yet.
+func attachTagsByName(ctx context.Context, q *Queries, todoID, userID int64, names []string) error {
+	for _, name := range names {
+		result, err := q.UpsertTag(ctx, UpsertTagParams{
+			UserID: userID,
+			Name:   name,
+		})
+		if err != nil {
+			return err
+		}
+		tagID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		err = q.AttachTag(ctx, AttachTagParams{
+			TodoID: todoID,
+			TagID:  tagID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateTodoTx creates a todo together with its tags within a single database
+// transaction. Nothing is written when any of the steps fails.
+func (repo *SQLRepo) CreateTodoTx(ctx context.Context, arg CreateTodoTxParams) (CreateTodoTxResult, error) {
+	var result CreateTodoTxResult
+
+	err := repo.execTx(ctx, func(q *Queries) error {
+		created, err := q.CreateTodo(ctx, arg.CreateTodoParams)
+		if err != nil {
+			return err
+		}
+		todoID, err := created.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		err = attachTagsByName(ctx, q, todoID, arg.UserID, arg.TagNames)
+		if err != nil {
+			return err
+		}
+
+		result.Todo, err = q.GetTodo(ctx, GetTodoParams{
+			ID:     todoID,
+			UserID: arg.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Tags, err = q.ListTagsByTodo(ctx, ListTagsByTodoParams{
+			TodoID: todoID,
+			UserID: arg.UserID,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// ReplaceTodoTagsTxParams contains the input parameters of ReplaceTodoTagsTx.
+type ReplaceTodoTagsTxParams struct {
+	TodoID   int64    `json:"todo_id"`
+	UserID   int64    `json:"user_id"`
+	TagNames []string `json:"tag_names"`
+}
+
+// ReplaceTodoTagsTx swaps the tags of a todo for the named tags within a
+// single database transaction and returns the new tags. It fails with
+// sql.ErrNoRows when the todo does not belong to the user or is in the trash.
+func (repo *SQLRepo) ReplaceTodoTagsTx(ctx context.Context, arg ReplaceTodoTagsTxParams) ([]Tag, error) {
+	var tags []Tag
+
+	err := repo.execTx(ctx, func(q *Queries) error {
+		todo, err := q.GetTodo(ctx, GetTodoParams{
+			ID:     arg.TodoID,
+			UserID: arg.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.DetachAllTags(ctx, todo.ID)
+		if err != nil {
+			return err
+		}
+
+		err = attachTagsByName(ctx, q, todo.ID, arg.UserID, arg.TagNames)
+		if err != nil {
+			return err
+		}
+
+		tags, err = q.ListTagsByTodo(ctx, ListTagsByTodoParams{
+			TodoID: todo.ID,
+			UserID: arg.UserID,
+		})
+		return err
+	})
+
+	return tags, err
+}
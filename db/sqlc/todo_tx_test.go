@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"first-app/todo_go/util"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecTxRollback(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	errBoom := errors.New("boom")
+
+	err := repo.execTx(context.Background(), func(q *Queries) error {
+		_, err := q.CreateTodo(context.Background(), CreateTodoParams{
+			UserID:      user.ID,
+			Title:       util.RandomTitle(),
+			Description: util.RandomDescription(),
+			Priority:    TodoPriorityMedium,
+		})
+		require.NoError(t, err)
+		return errBoom
+	})
+	require.ErrorIs(t, err, errBoom)
+
+	total, err := repo.CountTodo(context.Background(), TodoFilter{UserID: user.ID})
+	require.NoError(t, err)
+	require.Zero(t, total)
+}
+
+func TestCreateTodoTx(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+
+	arg := CreateTodoTxParams{
+		CreateTodoParams: CreateTodoParams{
+			UserID:      user.ID,
+			Title:       util.RandomTitle(),
+			Description: util.RandomDescription(),
+			Priority:    TodoPriorityHigh,
+		},
+		TagNames: []string{"work", "home", "work"},
+	}
+	result, err := repo.CreateTodoTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Title, result.Todo.Title)
+	require.Equal(t, TodoPriorityHigh, result.Todo.Priority)
+	require.Len(t, result.Tags, 2)
+	require.Equal(t, "home", result.Tags[0].Name)
+	require.Equal(t, "work", result.Tags[1].Name)
+
+	// Existing tags are reused.
+	arg.TagNames = []string{"work"}
+	second, err := repo.CreateTodoTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, second.Tags, 1)
+	require.Equal(t, result.Tags[1].ID, second.Tags[0].ID)
+}
+
+func TestCreateTodoTxRollback(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+
+	// The second tag name is longer than the column, so the insert fails
+	// after the todo and the first tag have been written.
+	arg := CreateTodoTxParams{
+		CreateTodoParams: CreateTodoParams{
+			UserID:      user.ID,
+			Title:       util.RandomTitle(),
+			Description: util.RandomDescription(),
+			Priority:    TodoPriorityMedium,
+		},
+		TagNames: []string{"work", strings.Repeat("a", 65)},
+	}
+	_, err := repo.CreateTodoTx(context.Background(), arg)
+	require.Error(t, err)
+
+	total, err := repo.CountTodo(context.Background(), TodoFilter{UserID: user.ID})
+	require.NoError(t, err)
+	require.Zero(t, total)
+
+	_, err = repo.GetTagByName(context.Background(), GetTagByNameParams{UserID: user.ID, Name: "work"})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestReplaceTodoTagsTx(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	todoId := createRandomTodo(t, repo, user)
+
+	tags, err := repo.ReplaceTodoTagsTx(context.Background(), ReplaceTodoTagsTxParams{
+		TodoID:   todoId,
+		UserID:   user.ID,
+		TagNames: []string{"work", "urgent"},
+	})
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+
+	tags, err = repo.ReplaceTodoTagsTx(context.Background(), ReplaceTodoTagsTxParams{
+		TodoID:   todoId,
+		UserID:   user.ID,
+		TagNames: []string{"home"},
+	})
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	require.Equal(t, "home", tags[0].Name)
+
+	other := createRandomUser(t, repo)
+	_, err = repo.ReplaceTodoTagsTx(context.Background(), ReplaceTodoTagsTxParams{
+		TodoID:   todoId,
+		UserID:   other.ID,
+		TagNames: []string{"stolen"},
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestReplaceTodoTagsTxRollback(t *testing.T) {
+	repo := newTestRepo(t)
+	user := createRandomUser(t, repo)
+	todoId := createRandomTodo(t, repo, user)
+
+	_, err := repo.ReplaceTodoTagsTx(context.Background(), ReplaceTodoTagsTxParams{
+		TodoID:   todoId,
+		UserID:   user.ID,
+		TagNames: []string{"work"},
+	})
+	require.NoError(t, err)
+
+	// The old tags are detached before the failing insert, the rollback has
+	// to bring them back.
+	_, err = repo.ReplaceTodoTagsTx(context.Background(), ReplaceTodoTagsTxParams{
+		TodoID:   todoId,
+		UserID:   user.ID,
+		TagNames: []string{"home", strings.Repeat("a", 65)},
+	})
+	require.Error(t, err)
+
+	tags, err := repo.ListTagsByTodo(context.Background(), ListTagsByTodoParams{TodoID: todoId, UserID: user.ID})
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	require.Equal(t, "work", tags[0].Name)
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+	"first-app/todo_go/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomUser(t *testing.T, q Querier) User {
+	hashedPassword, err := util.HashPassword(util.RandomString(10))
+	require.NoError(t, err)
+
+	arg := CreateUserParams{
+		Username:       util.RandomUsername(),
+		HashedPassword: hashedPassword,
+	}
+
+	result, err := q.CreateUser(context.Background(), arg)
+	require.NoError(t, err)
+	id, _ := result.LastInsertId()
+
+	user, err := q.GetUser(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, user.Username)
+	require.Equal(t, arg.HashedPassword, user.HashedPassword)
+
+	return user
+}
+
+func TestCreateUser(t *testing.T) {
+	repo := newTestRepo(t)
+	createRandomUser(t, repo)
+}
+
+func TestGetUserByUsername(t *testing.T) {
+	repo := newTestRepo(t)
+	user1 := createRandomUser(t, repo)
+	user2, err := repo.GetUserByUsername(context.Background(), user1.Username)
+	require.NoError(t, err)
+	require.Equal(t, user1, user2)
+}
@@ -1,39 +1,95 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"first-app/todo_go/api"
-	db "first-app/todo_go/db/sqlc"
 	"first-app/todo_go/util"
+	"flag"
 	"fmt"
-	"log"
-
-	_ "github.com/go-sql-driver/mysql"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
 	config, err := util.LoadConfig("..")
 	if err != nil {
-		log.Fatal("cannot load config:", err)
+		slog.Error("Cannot load config", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	logger, err := util.NewLogger(os.Stdout, config.LogFormat, config.LogLevel)
+	if err != nil {
+		slog.Error("Cannot create logger", slog.Any("error", err))
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+
+	storageFlag := flag.String("storage", storageDB, "where to keep the data: db, or memory for tests and demos")
+	flag.Parse()
+
+	if args := flag.Args(); len(args) > 0 && args[0] == "migrate" {
+		if err := runMigrate(config, args[1:], os.Stdout); err != nil {
+			slog.Error("Cannot migrate db", slog.Any("error", err))
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := config.Validate(); err != nil {
+		slog.Error("Invalid config", slog.Any("error", err))
+		os.Exit(1)
 	}
 
-	dbSource := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/todo?parseTime=true&loc=Asia%%2FTokyo",
-		config.DBUser,
-		config.DBPassword,
-		config.DBHost,
-		config.DBPort,
-	)
-	conn, err := sql.Open(config.DBDriver, dbSource)
+	// Stop on SIGINT or SIGTERM: the server drains its in-flight requests and
+	// the trash purge finishes its run before the storage is closed.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store, err := openStorage(ctx, config, *storageFlag)
 	if err != nil {
-		log.Fatal("Cannot connect to db: ", err)
+		slog.Error("Cannot open storage", slog.Any("error", err))
+		os.Exit(1)
 	}
+	repo := store.repo
 
-	repo := db.NewRepo(conn)
-	server := api.NewServer(repo, false)
+	purgeDone := make(chan struct{})
+	go func() {
+		defer close(purgeDone)
+		api.RunTrashPurge(ctx, repo, config.TrashRetention, time.Hour)
+	}()
+
+	secrets := api.Secrets{
+		SessionKeys: config.SessionKeys,
+		CSRFSecret:  config.CSRFSecret,
+	}
+	options := []api.Option{
+		api.WithSecrets(secrets),
+		api.WithMigrations(store.migrations),
+		api.WithPageSize(config.PageSize, config.MaxPageSize),
+	}
+	if len(config.TemplateDir) > 0 {
+		options = append(options, api.WithTemplateDir(config.TemplateDir))
+	}
+	server := api.NewServer(repo, options...)
 
-	err = server.Start(fmt.Sprintf("%s:%s", config.ServerAddress, config.ServerPort))
+	timeouts := api.HTTPTimeouts{
+		Read:     config.ServerReadTimeout,
+		Write:    config.ServerWriteTimeout,
+		Idle:     config.ServerIdleTimeout,
+		Shutdown: config.ServerShutdownTimeout,
+	}
+	err = server.Start(ctx, fmt.Sprintf("%s:%s", config.ServerAddress, config.ServerPort), timeouts)
+	stop()
+	<-purgeDone
+	if closeErr := store.close(); closeErr != nil {
+		slog.Error("Cannot close storage", slog.Any("error", closeErr))
+	}
 	if err != nil {
-		log.Fatal("Cannot start server: ", err)
+		slog.Error("Server failed", slog.Any("error", err))
+		os.Exit(1)
 	}
+	slog.Info("Server stopped")
 }
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	db "first-app/todo_go/db/sqlc"
+	"first-app/todo_go/util"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+)
+
+const migrateUsage = "usage: todo migrate up|down [N]|status|version"
+
+// runMigrate runs the migrate subcommand: up applies the pending migrations,
+// down rolls back the last N (1 by default), status lists every migration
+// and version prints the current one.
+func runMigrate(config util.Config, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	steps := 1
+	switch args[0] {
+	case "up", "status", "version":
+	case "down":
+		if len(args) > 1 {
+			var err error
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return fmt.Errorf("invalid number of migrations %q, %s", args[1], migrateUsage)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q, %s", args[0], migrateUsage)
+	}
+
+	migrator, err := db.NewMigrator(config)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	switch args[0] {
+	case "up":
+		err = migrator.Up()
+	case "down":
+		err = migrator.Down(steps)
+	case "status":
+		return printMigrationStatus(migrator, out)
+	}
+	if err != nil {
+		return err
+	}
+
+	version, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "version %d", version.Version)
+	if version.Dirty {
+		fmt.Fprint(out, " (dirty)")
+	}
+	fmt.Fprintln(out)
+	return nil
+}
+
+func printMigrationStatus(migrator *db.Migrator, out io.Writer) error {
+	items, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		state := "pending"
+		if item.Applied {
+			state = "applied"
+		}
+		fmt.Fprintf(out, "%06d %-8s %s\n", item.Version, state, item.Name)
+	}
+	return nil
+}
+
+// autoMigrate applies the pending migrations before the server starts.
+func autoMigrate(config util.Config) error {
+	migrator, err := db.NewMigrator(config)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	if err := migrator.Up(); err != nil {
+		return err
+	}
+	version, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+	slog.Info("Database migrated", slog.Int64("version", version.Version))
+	return nil
+}
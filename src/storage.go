@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"first-app/todo_go/api"
+	memdb "first-app/todo_go/db/memory"
+	"first-app/todo_go/db/migration"
+	db "first-app/todo_go/db/sqlc"
+	"first-app/todo_go/util"
+	"fmt"
+	"io/fs"
+	"log/slog"
+)
+
+const (
+	storageDB     = "db"
+	storageMemory = "memory"
+)
+
+// storage is where the server keeps its data: the repo, the migrations readyz
+// checks its schema version against and the func that releases it.
+type storage struct {
+	repo       db.Repo
+	migrations fs.FS
+	close      func() error
+}
+
+// openStorage opens the storage of the --storage flag. The db storage
+// connects to the database of DB_DRIVER and migrates it when
+// DB_AUTO_MIGRATE is set, the memory storage starts empty every time.
+func openStorage(ctx context.Context, config util.Config, kind string) (storage, error) {
+	switch kind {
+	case storageDB:
+		return openDBStorage(ctx, config)
+	case storageMemory:
+		slog.Warn("Keeping the data in memory, it is lost when the server stops")
+		migrations, err := migration.ForDriver("mysql")
+		if err != nil {
+			return storage{}, err
+		}
+		return storage{
+			repo:       memdb.New(),
+			migrations: migrations,
+			close:      func() error { return nil },
+		}, nil
+	}
+	return storage{}, fmt.Errorf("unknown storage %q, expected %s or %s", kind, storageDB, storageMemory)
+}
+
+func openDBStorage(ctx context.Context, config util.Config) (s storage, err error) {
+	conn, err := db.Connect(ctx, config)
+	if err != nil {
+		return storage{}, fmt.Errorf("cannot connect to db: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			conn.Close()
+		}
+	}()
+
+	if config.DBAutoMigrate {
+		if err := autoMigrate(config); err != nil {
+			return storage{}, fmt.Errorf("cannot migrate db: %w", err)
+		}
+	}
+
+	if err := api.RegisterDBStats(conn); err != nil {
+		return storage{}, fmt.Errorf("cannot register db metrics: %w", err)
+	}
+
+	repo, err := db.NewDriverRepo(config.DBDriver, conn, api.ObserveQuery)
+	if err != nil {
+		return storage{}, fmt.Errorf("cannot create repo: %w", err)
+	}
+	migrations, err := migration.ForDriver(config.DBDriver)
+	if err != nil {
+		return storage{}, fmt.Errorf("cannot load migrations: %w", err)
+	}
+
+	return storage{repo: repo, migrations: migrations, close: conn.Close}, nil
+}
@@ -0,0 +1,11 @@
+// Package static embeds the CSS, JavaScript and fonts the pages load, so the
+// app works without reaching a CDN.
+package static
+
+import "embed"
+
+// FS holds the vendored libraries under lib, each in a directory named with
+// its version so the files can be cached for good.
+//
+//go:embed lib
+var FS embed.FS
@@ -0,0 +1,55 @@
+#!/bin/sh
+# Updates the vendored libraries committed under static/lib. Every file is
+# checked against the subresource integrity hash the templates use, and the
+# missing or changed ones are downloaded again. With "check" it only reports
+# the files that do not match.
+#
+# The build does not need it: run it after bumping a version here and in the
+# templates, then commit the new files.
+set -eu
+
+mode=${1:-update}
+
+cd "$(dirname "$0")/lib"
+failed=0
+
+sri() {
+	echo "sha384-$(openssl dgst -sha384 -binary "$1" | openssl base64 -A)"
+}
+
+fetch() {
+	path=$1
+	url=$2
+	integrity=$3
+
+	if [ -f "$path" ] && [ "$(sri "$path")" = "$integrity" ]; then
+		return
+	fi
+	if [ "$mode" = check ]; then
+		echo "integrity mismatch for $path: want $integrity" >&2
+		failed=1
+		return
+	fi
+
+	mkdir -p "$(dirname "$path")"
+	curl -fsSL -o "$path" "$url"
+	hash=$(sri "$path")
+	if [ "$hash" != "$integrity" ]; then
+		rm -f "$path"
+		echo "integrity mismatch for $path: got $hash, want $integrity" >&2
+		exit 1
+	fi
+	echo "fetched $path"
+}
+
+fetch bootstrap-4.6.1/css/bootstrap.min.css \
+	https://cdn.jsdelivr.net/npm/bootstrap@4.6.1/dist/css/bootstrap.min.css \
+	sha384-zCbKRCUGaJDkqS1kPbPd7TveP5iyJE0EjAuZQTgFLD2ylzuqKfdKlfG/eSrtxUkn
+fetch bootstrap-4.6.1/js/bootstrap.bundle.min.js \
+	https://cdn.jsdelivr.net/npm/bootstrap@4.6.1/dist/js/bootstrap.bundle.min.js \
+	sha384-fQybjgWLrvvRgtW6bFlB7jaZrFsaBXjsOMm/tB9LTS58ONXgqbR9W8oWht/amnpF
+fetch jquery-3.5.1/jquery.slim.min.js \
+	https://cdn.jsdelivr.net/npm/jquery@3.5.1/dist/jquery.slim.min.js \
+	sha384-DfXdz2htPH0lsSSs5nCTpuj/zy4C+OGpamoFVy38MVBnE+IbbVYUew+OrCXaRkfj
+
+exit $failed
@@ -0,0 +1,9 @@
+// Package templates embeds the HTML templates into the binary.
+package templates
+
+import "embed"
+
+// FS holds the HTML templates, one page or partial per file.
+//
+//go:embed *.html
+var FS embed.FS
@@ -1,17 +1,108 @@
 package util
 
-import "github.com/spf13/viper"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 // Config stores all configuration of the application.
 // The values are ready by viper from a config file or environment variables.
 type Config struct {
-	DBDriver      string `mapstructure:"DB_DRIVER"`
-	DBUser        string `mapstructure:"DB_USER"`
-	DBPassword    string `mapstructure:"DB_PASSWORD"`
-	DBHost        string `mapstructure:"DB_HOST"`
-	DBPort        string `mapstructure:"DB_PORT"`
+	// Environment is development or production. Production refuses to start
+	// with the default secrets.
+	Environment string `mapstructure:"ENVIRONMENT"`
+	DBDriver    string `mapstructure:"DB_DRIVER"`
+	DBUser      string `mapstructure:"DB_USER"`
+	DBPassword  string `mapstructure:"DB_PASSWORD"`
+	DBHost      string `mapstructure:"DB_HOST"`
+	DBPort      string `mapstructure:"DB_PORT"`
+	DBName      string `mapstructure:"DB_NAME"`
+	// DBTimezone is the location the DATETIME columns are read in.
+	DBTimezone string `mapstructure:"DB_TIMEZONE"`
+	// DBTLSMode is false, true, skip-verify or preferred. With true, DBTLSCA
+	// can name a PEM file with the CA to verify the server with instead of
+	// the system roots.
+	DBTLSMode        string        `mapstructure:"DB_TLS_MODE"`
+	DBTLSCA          string        `mapstructure:"DB_TLS_CA"`
+	DBConnectTimeout time.Duration `mapstructure:"DB_CONNECT_TIMEOUT"`
+	// DBConnectRetries is how many more times the startup tries to reach the
+	// database before giving up, waiting longer after every failure.
+	DBConnectRetries  int           `mapstructure:"DB_CONNECT_RETRIES"`
+	DBMaxOpenConns    int           `mapstructure:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns    int           `mapstructure:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetime time.Duration `mapstructure:"DB_CONN_MAX_LIFETIME"`
+	// DBAutoMigrate applies the pending migrations when the server starts.
+	DBAutoMigrate bool   `mapstructure:"DB_AUTO_MIGRATE"`
 	ServerAddress string `mapstructure:"SERVER_ADDRESS"`
 	ServerPort    string `mapstructure:"SERVER_PORT"`
+	// The HTTP server timeouts. ServerShutdownTimeout is how long in-flight
+	// requests may take to finish once SIGINT or SIGTERM is received.
+	ServerReadTimeout     time.Duration `mapstructure:"SERVER_READ_TIMEOUT"`
+	ServerWriteTimeout    time.Duration `mapstructure:"SERVER_WRITE_TIMEOUT"`
+	ServerIdleTimeout     time.Duration `mapstructure:"SERVER_IDLE_TIMEOUT"`
+	ServerShutdownTimeout time.Duration `mapstructure:"SERVER_SHUTDOWN_TIMEOUT"`
+	// TrashRetention is how long deleted todos stay in the trash before they
+	// are purged for good. Zero keeps them forever.
+	TrashRetention time.Duration `mapstructure:"TRASH_RETENTION"`
+	// PageSize is how many todos the lists show per page unless per_page
+	// asks for another size, which is capped at MaxPageSize.
+	PageSize    int `mapstructure:"PAGE_SIZE"`
+	MaxPageSize int `mapstructure:"MAX_PAGE_SIZE"`
+	// LogFormat is json or logfmt, LogLevel one of debug, info, warn or error.
+	LogFormat string `mapstructure:"LOG_FORMAT"`
+	LogLevel  string `mapstructure:"LOG_LEVEL"`
+	// SessionKeys sign the session cookies, comma separated. New sessions are
+	// signed with the first key; the others still verify the sessions signed
+	// before a rotation, so put the new key first and drop the old one later.
+	SessionKeys []string `mapstructure:"SESSION_KEYS"`
+	// CSRFSecret signs the CSRF tokens of the HTML forms.
+	CSRFSecret string `mapstructure:"CSRF_SECRET"`
+	// TemplateDir, when set, serves the HTML templates from this directory
+	// instead of the embedded ones and reloads them on every render.
+	TemplateDir string `mapstructure:"TEMPLATE_DIR"`
+}
+
+const (
+	// The development secrets shipped in app.env. They are public, so they
+	// must never sign anything in production.
+	DefaultSessionKey = "secret"
+	DefaultCSRFSecret = "secret123"
+
+	// minSecretLen is the shortest secret accepted in production.
+	minSecretLen = 32
+)
+
+// IsProduction reports if the app runs in production mode.
+func (config Config) IsProduction() bool {
+	return strings.EqualFold(config.Environment, "production")
+}
+
+// Validate checks that the secrets are set, and in production that they are
+// neither the development defaults nor too short to be safe.
+func (config Config) Validate() error {
+	if len(config.SessionKeys) == 0 {
+		return errors.New("SESSION_KEYS is not set")
+	}
+	if len(config.CSRFSecret) == 0 {
+		return errors.New("CSRF_SECRET is not set")
+	}
+	if !config.IsProduction() {
+		return nil
+	}
+
+	for i, key := range config.SessionKeys {
+		if key == DefaultSessionKey || len(key) < minSecretLen {
+			return fmt.Errorf("SESSION_KEYS key %d must be a random value of at least %d characters in production", i+1, minSecretLen)
+		}
+	}
+	if config.CSRFSecret == DefaultCSRFSecret || len(config.CSRFSecret) < minSecretLen {
+		return fmt.Errorf("CSRF_SECRET must be a random value of at least %d characters in production", minSecretLen)
+	}
+	return nil
 }
 
 // LoadConfig reads configuration from file or environment variables.
@@ -20,6 +111,22 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.SetConfigName("app")
 	viper.SetConfigType("env") // json, xml
 
+	viper.SetDefault("DB_NAME", "todo")
+	viper.SetDefault("DB_TIMEZONE", "Asia/Tokyo")
+	viper.SetDefault("DB_TLS_MODE", "false")
+	viper.SetDefault("DB_CONNECT_TIMEOUT", 5*time.Second)
+	viper.SetDefault("DB_CONNECT_RETRIES", 5)
+	viper.SetDefault("DB_MAX_OPEN_CONNS", 25)
+	viper.SetDefault("DB_MAX_IDLE_CONNS", 25)
+	viper.SetDefault("DB_CONN_MAX_LIFETIME", 5*time.Minute)
+	viper.SetDefault("DB_AUTO_MIGRATE", false)
+	viper.SetDefault("SERVER_READ_TIMEOUT", 10*time.Second)
+	viper.SetDefault("SERVER_WRITE_TIMEOUT", 30*time.Second)
+	viper.SetDefault("SERVER_IDLE_TIMEOUT", 2*time.Minute)
+	viper.SetDefault("SERVER_SHUTDOWN_TIMEOUT", 15*time.Second)
+	viper.SetDefault("PAGE_SIZE", 5)
+	viper.SetDefault("MAX_PAGE_SIZE", 100)
+
 	viper.AutomaticEnv()
 
 	err = viper.ReadInConfig()
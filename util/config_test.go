@@ -0,0 +1,74 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigSessionKeys(t *testing.T) {
+	t.Setenv("SESSION_KEYS", "new-key,old-key")
+
+	config, err := LoadConfig("..")
+	require.NoError(t, err)
+	require.Equal(t, []string{"new-key", "old-key"}, config.SessionKeys)
+}
+
+func TestValidateConfig(t *testing.T) {
+	strongKey := strings.Repeat("k", minSecretLen)
+	strongSecret := strings.Repeat("s", minSecretLen)
+
+	testCases := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{
+			name:   "Development Defaults",
+			config: Config{SessionKeys: []string{DefaultSessionKey}, CSRFSecret: DefaultCSRFSecret},
+		},
+		{
+			name:    "No Session Keys",
+			config:  Config{CSRFSecret: DefaultCSRFSecret},
+			wantErr: true,
+		},
+		{
+			name:    "No CSRF Secret",
+			config:  Config{SessionKeys: []string{DefaultSessionKey}},
+			wantErr: true,
+		},
+		{
+			name:   "Production",
+			config: Config{Environment: "production", SessionKeys: []string{strongKey, strongKey + "old"}, CSRFSecret: strongSecret},
+		},
+		{
+			name:    "Production Default Session Key",
+			config:  Config{Environment: "production", SessionKeys: []string{strongKey, DefaultSessionKey}, CSRFSecret: strongSecret},
+			wantErr: true,
+		},
+		{
+			name:    "Production Default CSRF Secret",
+			config:  Config{Environment: "Production", SessionKeys: []string{strongKey}, CSRFSecret: DefaultCSRFSecret},
+			wantErr: true,
+		},
+		{
+			name:    "Production Short Key",
+			config:  Config{Environment: "production", SessionKeys: []string{"short"}, CSRFSecret: strongSecret},
+			wantErr: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -1,7 +1,50 @@
 package util
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
 
-func SetErrorDetails(cause, effect, solution, position string) string {
-	return fmt.Sprintf("[Cause] %s\n[Effect] %s\n[Solution] %s\n[Position] %s:", cause, effect, solution, position)
+// ErrorDetails describes a failure for the people reading the logs: what went
+// wrong, what the user sees because of it, how to fix it and where it happened.
+// Every field is logged under its own key.
+type ErrorDetails struct {
+	Cause    string
+	Effect   string
+	Solution string
+	Position string
+}
+
+// Attrs returns the details as key value pairs for a slog call.
+func (d ErrorDetails) Attrs() []any {
+	return []any{
+		slog.String("cause", d.Cause),
+		slog.String("effect", d.Effect),
+		slog.String("solution", d.Solution),
+		slog.String("position", d.Position),
+	}
+}
+
+// NewLogger builds the application logger writing to w. The format is json or
+// logfmt, the level is one of debug, info, warn or error. Empty values fall
+// back to json and info.
+func NewLogger(w io.Writer, format, level string) (*slog.Logger, error) {
+	var logLevel slog.Level
+	if len(level) > 0 {
+		if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+		}
+	}
+	options := &slog.HandlerOptions{Level: logLevel}
+
+	switch strings.ToLower(format) {
+	case "", "json":
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	case "logfmt", "text":
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q, expected json or logfmt", format)
+	}
 }
@@ -0,0 +1,77 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogger(t *testing.T) {
+	testCases := []struct {
+		name     string
+		format   string
+		level    string
+		checkLog func(t *testing.T, out string, err error)
+	}{
+		{
+			name: "Defaults",
+			checkLog: func(t *testing.T, out string, err error) {
+				require.NoError(t, err)
+				var entry map[string]any
+				require.NoError(t, json.Unmarshal([]byte(out), &entry))
+				require.Equal(t, "INFO", entry["level"])
+				require.Equal(t, "cause", entry["cause"])
+			},
+		},
+		{
+			name:   "Logfmt",
+			format: "logfmt",
+			level:  "warn",
+			checkLog: func(t *testing.T, out string, err error) {
+				require.NoError(t, err)
+				require.Empty(t, out)
+			},
+		},
+		{
+			name:   "Logfmt Debug",
+			format: "logfmt",
+			level:  "debug",
+			checkLog: func(t *testing.T, out string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, out, "level=INFO")
+				require.Contains(t, out, "cause=cause")
+				require.Contains(t, out, `effect="the effect"`)
+			},
+		},
+		{
+			name:   "Invalid Format",
+			format: "xml",
+			checkLog: func(t *testing.T, out string, err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name:  "Invalid Level",
+			level: "loud",
+			checkLog: func(t *testing.T, out string, err error) {
+				require.Error(t, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := NewLogger(&buf, tc.format, tc.level)
+			if err == nil {
+				details := ErrorDetails{Cause: "cause", Effect: "the effect", Solution: "solution", Position: "position"}
+				logger.Info(details.Cause, details.Attrs()...)
+			}
+			tc.checkLog(t, buf.String(), err)
+		})
+	}
+}
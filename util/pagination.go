@@ -1,40 +1,62 @@
 package util
 
+// GetPageInfo returns the navigation of page in a list of total items shown
+// limit per page. The page links come in fixed windows of navLen pages: with
+// a navLen of 5, pages 1 to 5 link to pages 1-5, pages 6 to 10 to pages 6-10
+// and so on.
+//
+// The page is clamped to the pages that exist, page 1 for an empty list, so
+// callers can redirect when PageInfo.Page differs from the page asked for.
 func GetPageInfo(page, navLen int, total, limit int64) PageInfo {
-	totalPage := total / limit
-	if total%limit != 0 {
-		totalPage += 1
+	if navLen < 1 {
+		navLen = 1
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	var totalPage int64
+	if total > 0 {
+		totalPage = (total + limit - 1) / limit
 	}
 
-	firstPage := ((page / navLen) * navLen)
-	lastPage := firstPage
-	if firstPage+navLen < int(totalPage) {
-		lastPage += navLen
-	} else {
-		lastPage += int(totalPage) % navLen
+	if int64(page) > totalPage {
+		page = int(totalPage)
+	}
+	if page < 1 {
+		page = 1
 	}
 
-	pageSlice := []Page{}
-	for i := firstPage; i < lastPage; i++ {
-		pageSlice = append(pageSlice, Page{i + 1, (i + 1) == page})
+	info := PageInfo{Page: page, TotalPage: totalPage, NavLen: navLen}
+	if totalPage == 0 {
+		return info
 	}
 
-	if len(pageSlice) == 0 {
-		return PageInfo{}
+	info.FirstPage = (page-1)/navLen*navLen + 1
+	info.LastPage = min(info.FirstPage+navLen-1, int(totalPage))
+	for i := info.FirstPage; i <= info.LastPage; i++ {
+		info.PageSlice = append(info.PageSlice, Page{i, i == page})
 	}
 
-	return PageInfo{
-		PageSlice: pageSlice,
-		TotalPage: totalPage,
-		NavLen:    navLen,
-		FirstPage: pageSlice[0].PageNum,
-		LastPage:  pageSlice[len(pageSlice)-1].PageNum,
-		Previous:  pageSlice[0].PageNum - navLen,
-		Next:      pageSlice[len(pageSlice)-1].PageNum + 1,
+	if info.FirstPage > 1 {
+		info.Previous = info.FirstPage - navLen
+	}
+	if int64(info.LastPage) < totalPage {
+		info.Next = info.LastPage + 1
+	}
+	if page > 1 {
+		info.PrevPage = page - 1
+	}
+	if int64(page) < totalPage {
+		info.NextPage = page + 1
 	}
+	return info
 }
 
+// PageInfo is the navigation of a numbered list. Previous and Next are the
+// first pages of the windows around PageSlice, PrevPage and NextPage the
+// pages around Page. Each of them is 0 when there is no such page.
 type PageInfo struct {
+	Page      int
 	PageSlice []Page
 	TotalPage int64
 	NavLen    int
@@ -42,6 +64,8 @@ type PageInfo struct {
 	LastPage  int
 	Previous  int
 	Next      int
+	PrevPage  int
+	NextPage  int
 }
 
 type Page struct {
@@ -0,0 +1,151 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPageInfo(t *testing.T) {
+	testCases := []struct {
+		name      string
+		page      int
+		total     int64
+		wantPage  int
+		wantPages []int
+		totalPage int64
+		previous  int
+		next      int
+		prevPage  int
+		nextPage  int
+	}{
+		{
+			name:      "First Page",
+			page:      1,
+			total:     60,
+			wantPage:  1,
+			wantPages: []int{1, 2, 3, 4, 5},
+			totalPage: 12,
+			next:      6,
+			nextPage:  2,
+		},
+		{
+			name:      "Last Page Of A Window",
+			page:      5,
+			total:     60,
+			wantPage:  5,
+			wantPages: []int{1, 2, 3, 4, 5},
+			totalPage: 12,
+			next:      6,
+			prevPage:  4,
+			nextPage:  6,
+		},
+		{
+			name:      "First Page Of A Window",
+			page:      6,
+			total:     60,
+			wantPage:  6,
+			wantPages: []int{6, 7, 8, 9, 10},
+			totalPage: 12,
+			previous:  1,
+			next:      11,
+			prevPage:  5,
+			nextPage:  7,
+		},
+		{
+			name:      "Last Window",
+			page:      12,
+			total:     60,
+			wantPage:  12,
+			wantPages: []int{11, 12},
+			totalPage: 12,
+			previous:  6,
+			prevPage:  11,
+		},
+		{
+			name:      "Full Last Window",
+			page:      10,
+			total:     50,
+			wantPage:  10,
+			wantPages: []int{6, 7, 8, 9, 10},
+			totalPage: 10,
+			previous:  1,
+			prevPage:  9,
+		},
+		{
+			name:      "Partial Last Page",
+			page:      3,
+			total:     11,
+			wantPage:  3,
+			wantPages: []int{1, 2, 3},
+			totalPage: 3,
+			prevPage:  2,
+		},
+		{
+			name:      "Single Page",
+			page:      1,
+			total:     5,
+			wantPage:  1,
+			wantPages: []int{1},
+			totalPage: 1,
+		},
+		{
+			name:      "Past The Last Page",
+			page:      20,
+			total:     11,
+			wantPage:  3,
+			wantPages: []int{1, 2, 3},
+			totalPage: 3,
+			prevPage:  2,
+		},
+		{
+			name:      "Zero Page",
+			page:      0,
+			total:     11,
+			wantPage:  1,
+			wantPages: []int{1, 2, 3},
+			totalPage: 3,
+			nextPage:  2,
+		},
+		{
+			name:      "Negative Page",
+			page:      -3,
+			total:     11,
+			wantPage:  1,
+			wantPages: []int{1, 2, 3},
+			totalPage: 3,
+			nextPage:  2,
+		},
+		{
+			name:     "Empty List",
+			page:     4,
+			total:    0,
+			wantPage: 1,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			info := GetPageInfo(tc.page, 5, tc.total, 5)
+			require.Equal(t, tc.wantPage, info.Page)
+			require.Equal(t, tc.totalPage, info.TotalPage)
+			require.Equal(t, tc.previous, info.Previous)
+			require.Equal(t, tc.next, info.Next)
+			require.Equal(t, tc.prevPage, info.PrevPage)
+			require.Equal(t, tc.nextPage, info.NextPage)
+
+			var pages []int
+			for _, page := range info.PageSlice {
+				pages = append(pages, page.PageNum)
+				require.Equal(t, page.PageNum == tc.wantPage, page.IsSelected)
+			}
+			require.Equal(t, tc.wantPages, pages)
+			if len(pages) > 0 {
+				require.Equal(t, pages[0], info.FirstPage)
+				require.Equal(t, pages[len(pages)-1], info.LastPage)
+			}
+		})
+	}
+}
@@ -36,3 +36,7 @@ func RandomTitle() sql.NullString {
 func RandomDescription() sql.NullString {
 	return sql.NullString{String: RandomString(15), Valid: true}
 }
+
+func RandomUsername() string {
+	return RandomString(8)
+}